@@ -41,10 +41,13 @@ EXAMPLE
 	package main
 	import (
 		"github.com/StefanSchroeder/Gocal"
+		"log"
 	)
 	func main() {
 		g := gocal.New(1,12,2010)
-		g.CreateCalendar("test-example01.pdf")
+		if err := g.CreateCalendar("test-example01.pdf"); err != nil {
+			log.Fatal(err)
+		}
 	}
 */
 package gocal
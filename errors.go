@@ -0,0 +1,63 @@
+package gocal
+
+// Copyright (c) 2014 Stefan Schroeder, NY, 2014-03-10
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file
+//
+// errors.go
+//
+// This file is part of gocal, a PDF calendar generator in Go.
+//
+// https://github.com/StefanSchroeder/Gocal
+//
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The kinds of errors that the Create* functions return.
+// Test for them with errors.Is.
+var (
+	ErrFont   = errors.New("bad font")
+	ErrPhoto  = errors.New("unreadable photo")
+	ErrConfig = errors.New("malformed XML configuration")
+	ErrICS    = errors.New("bad ICS file")
+	ErrOutput = errors.New("unwritable output")
+	ErrRange  = errors.New("invalid month range")
+
+	ErrHolidays = errors.New("unknown holiday region")
+	ErrTimezone = errors.New("unknown time zone")
+	ErrTable    = errors.New("bad CSV or JSON file")
+	ErrVCard    = errors.New("bad vCard file")
+)
+
+// Error is the error type returned by the Create* functions.
+// Kind is one of the Err* values above, Path is the file or
+// URL that caused the failure and Err is the underlying error.
+type Error struct {
+	Kind error
+	Path string
+	Err  error
+}
+
+func (e *Error) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("gocal: %v: %v", e.Kind, e.Err)
+	}
+	if e.Err == nil {
+		return fmt.Sprintf("gocal: %v: %s", e.Kind, e.Path)
+	}
+	return fmt.Sprintf("gocal: %v: %s: %v", e.Kind, e.Path, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the error is of the given kind.
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
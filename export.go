@@ -0,0 +1,180 @@
+package gocal
+
+// Copyright (c) 2014 Stefan Schroeder, NY, 2014-03-10
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file
+//
+// export.go
+//
+// This file is part of gocal, a PDF calendar generator in Go.
+//
+// https://github.com/StefanSchroeder/Gocal
+//
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// icsOccurrence is an event on a day, as exported.
+type icsOccurrence struct {
+	first      time.Time
+	days       int
+	text       string
+	category   string
+	tags       []string
+	start, end time.Time // of timed events
+}
+
+// occurrences expands the events of the store to the days between
+// from and to. Events that repeat every year or every week get a day
+// each. Events over several days come first on their first day.
+func occurrences(store *eventStore, from, to time.Time, weekdayNames [8]string) (out []icsOccurrence) {
+	for _, ev := range store.multiDay {
+		first := time.Date(ev.Year, ev.Month, ev.Day, 0, 0, 0, 0, time.UTC)
+		last := first.AddDate(0, 0, ev.Days-1)
+		if first.Before(to) && !last.Before(from) {
+			out = append(out, icsOccurrence{first, ev.Days, ev.expand(first, weekdayNames[(first.Weekday()+1)%7]), ev.Category, ev.Tags, ev.Start, ev.End})
+		}
+	}
+	for t := from; t.Before(to); t = t.AddDate(0, 0, 1) {
+		for _, ev := range store.on(t) {
+			out = append(out, icsOccurrence{t, 1, ev.expand(t, weekdayNames[(t.Weekday()+1)%7]), ev.Category, ev.Tags, ev.Start, ev.End})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].first.Before(out[j].first) })
+	return out
+}
+
+// icsEscaper escapes the characters of text values.
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+
+// icsEscape escapes a text value.
+func icsEscape(s string) string {
+	s = strings.Replace(s, "\\n", "\n", -1) // the \n of the event files
+	return icsEscaper.Replace(strings.TrimSpace(s))
+}
+
+// icsWriter writes the content lines of an ICS file, folded to
+// 75 octets and ended with CRLF.
+type icsWriter struct {
+	w *bufio.Writer
+}
+
+func (iw icsWriter) line(s string) {
+	limit := 75
+	for len(s) > limit {
+		n := limit
+		for !utf8.RuneStart(s[n]) {
+			n--
+		}
+		iw.w.WriteString(s[:n] + "\r\n ")
+		s = s[n:]
+		limit = 74 // after the space
+	}
+	iw.w.WriteString(s + "\r\n")
+}
+
+// CreateICS writes the events of the calendar to the iCalendar
+// file fn.
+func (g *Calendar) CreateICS(fn string) error {
+	return writeFile(fn, g.CreateICSTo)
+}
+
+// CreateICSTo writes the events of the calendar range to w as an
+// iCalendar file: those of the event files, ICS files and AddEvent,
+// expanded to their days, timed events with their times, and with
+// SetExportMoon and SetExportHolidays the moon phases and the holidays.
+func (g *Calendar) CreateICSTo(w io.Writer) error {
+	monthList, err := g.months()
+	if err != nil {
+		return err
+	}
+	from, to := rangeBounds(monthList)
+	eventList, err := g.ownEvents(from, to)
+	if err != nil {
+		return err
+	}
+	if g.OptExportHolidays {
+		holidayList, _, err := g.holidayEvents(from, to)
+		if err != nil {
+			return err
+		}
+		eventList = append(eventList, holidayList...)
+	}
+	eventList = g.tagFilter(eventList)
+	language := getLanguage(g.OptLocale)
+	if g.OptExportMoon {
+		moons, err := g.moons(from, to)
+		if err != nil {
+			return err
+		}
+		for _, m := range moons.phases {
+			eventList = append(eventList, Event{Month: m.t.Month(), Day: m.t.Day(), Text: moonName(language, m.name), Year: m.t.Year(), Days: 1, Category: "moon"})
+		}
+	}
+	weekdayNames := getLocalizedWeekdayNames(language, 0)
+	list := occurrences(newEventStore(eventList), from, to, weekdayNames)
+
+	bw := bufio.NewWriter(w)
+	iw := icsWriter{bw}
+	iw.line("BEGIN:VCALENDAR")
+	iw.line("VERSION:2.0")
+	iw.line("PRODID:-//Gocal//Gocal//EN")
+	iw.line("CALSCALE:GREGORIAN")
+	iw.line("METHOD:PUBLISH")
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+	seen := map[string]int{}
+	for _, o := range list {
+		// The UID stays the same when the file is made again, so that
+		// subscribers update the events instead of adding them again.
+		sum := sha256.Sum256([]byte(o.first.Format("2006-01-02") + "\x00" + o.text + "\x00" + o.category))
+		uid := hex.EncodeToString(sum[:12])
+		seen[uid]++
+		if n := seen[uid]; n > 1 {
+			uid += "-" + strconv.Itoa(n)
+		}
+		iw.line("BEGIN:VEVENT")
+		iw.line("UID:" + uid + "@gocal")
+		iw.line("DTSTAMP:" + stamp)
+		if o.start.IsZero() {
+			iw.line("DTSTART;VALUE=DATE:" + o.first.Format("20060102"))
+			iw.line("DTEND;VALUE=DATE:" + o.first.AddDate(0, 0, o.days).Format("20060102"))
+		} else {
+			// Timed events keep their moments, in UTC.
+			end := o.end
+			if end.Before(o.start) {
+				end = o.start
+			}
+			iw.line("DTSTART:" + o.start.UTC().Format("20060102T150405Z"))
+			iw.line("DTEND:" + end.UTC().Format("20060102T150405Z"))
+		}
+		iw.line("SUMMARY:" + icsEscape(o.text))
+		var cats []string
+		dup := map[string]bool{"": true}
+		for _, c := range append([]string{o.category}, o.tags...) {
+			if !dup[strings.ToLower(c)] {
+				cats = append(cats, icsEscape(c))
+				dup[strings.ToLower(c)] = true
+			}
+		}
+		if len(cats) > 0 {
+			iw.line("CATEGORIES:" + strings.Join(cats, ","))
+		}
+		iw.line("TRANSP:TRANSPARENT")
+		iw.line("END:VEVENT")
+	}
+	iw.line("END:VCALENDAR")
+	if err := bw.Flush(); err != nil {
+		return &Error{ErrOutput, "", err}
+	}
+	return nil
+}
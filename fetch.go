@@ -0,0 +1,177 @@
+package gocal
+
+// Copyright (c) 2014 Stefan Schroeder, NY, 2014-03-10
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file
+//
+// fetch.go
+//
+// This file is part of gocal, a PDF calendar generator in Go.
+//
+// https://github.com/StefanSchroeder/Gocal
+//
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// isURL tells if the ICS source is fetched over the network.
+func isURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://") || strings.HasPrefix(s, "webcal://")
+}
+
+// cacheMeta is stored next to a cached feed for the revalidation
+// with the server.
+type cacheMeta struct {
+	URL          string
+	ETag         string
+	LastModified string
+}
+
+// cachePaths returns the files of the feed url in the cache.
+func (g *Calendar) cachePaths(url string) (data string, meta string) {
+	sum := sha256.Sum256([]byte(url))
+	name := hex.EncodeToString(sum[:8])
+	return filepath.Join(g.OptCacheDir, name+".ics"), filepath.Join(g.OptCacheDir, name+".json")
+}
+
+// openICS opens an ICS file, or fetches an http://, https:// or
+// webcal:// feed. With a cache directory the feed is only downloaded
+// again if the server reports a change; if the server cannot be
+// reached, and in offline mode, the cached copy is used.
+func (g *Calendar) openICS(filename string) (io.ReadCloser, error) {
+	if !isURL(filename) {
+		return os.Open(filename)
+	}
+	url := filename
+	if strings.HasPrefix(url, "webcal://") {
+		url = "https://" + strings.TrimPrefix(url, "webcal://")
+	}
+	if g.OptCacheDir == "" {
+		if g.OptOffline {
+			return nil, fmt.Errorf("offline without a cache")
+		}
+		data, _, err := g.fetch(url, cacheMeta{})
+		if err != nil {
+			return nil, err
+		}
+		return ioutil.NopCloser(bytes.NewReader(data)), nil
+	}
+
+	dataPath, metaPath := g.cachePaths(url)
+	var meta cacheMeta
+	cached, cacheErr := ioutil.ReadFile(dataPath)
+	if cacheErr == nil {
+		if b, err := ioutil.ReadFile(metaPath); err == nil {
+			json.Unmarshal(b, &meta)
+		}
+	}
+	if g.OptOffline {
+		if cacheErr != nil {
+			return nil, fmt.Errorf("not in the cache: %v", cacheErr)
+		}
+		return ioutil.NopCloser(bytes.NewReader(cached)), nil
+	}
+
+	data, newMeta, err := g.fetch(url, meta)
+	switch {
+	case err != nil && cacheErr == nil:
+		g.logf("%s: using the cached copy: %v", filename, err)
+		return ioutil.NopCloser(bytes.NewReader(cached)), nil
+	case err != nil:
+		return nil, err
+	case data == nil: // not modified
+		return ioutil.NopCloser(bytes.NewReader(cached)), nil
+	}
+	// Keep the last good copy if the server sends something else,
+	// e.g. a login page.
+	if !bytes.HasPrefix(bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))), []byte("BEGIN:VCALENDAR")) {
+		if cacheErr == nil {
+			g.logf("%s: using the cached copy: the server sent no ICS data", filename)
+			return ioutil.NopCloser(bytes.NewReader(cached)), nil
+		}
+		return ioutil.NopCloser(bytes.NewReader(data)), nil
+	}
+	newMeta.URL = url
+	if err := writeCache(dataPath, data); err != nil {
+		g.logf("%s: not cached: %v", filename, err)
+	} else if b, err := json.Marshal(newMeta); err == nil {
+		writeCache(metaPath, b)
+	}
+	return ioutil.NopCloser(bytes.NewReader(data)), nil
+}
+
+// fetch downloads url. If meta has the validators of a cached copy
+// and the server answers that the feed has not changed, data is nil.
+func (g *Calendar) fetch(url string, meta cacheMeta) (data []byte, newMeta cacheMeta, err error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, newMeta, err
+	}
+	if meta.ETag != "" {
+		req.Header.Set("If-None-Match", meta.ETag)
+	}
+	if meta.LastModified != "" {
+		req.Header.Set("If-Modified-Since", meta.LastModified)
+	}
+	timeout := g.OptTimeout
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, newMeta, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		if meta.ETag == "" && meta.LastModified == "" {
+			return nil, newMeta, fmt.Errorf("%s", resp.Status)
+		}
+		return nil, meta, nil
+	case http.StatusOK:
+	default:
+		return nil, newMeta, fmt.Errorf("%s", resp.Status)
+	}
+	if data, err = ioutil.ReadAll(resp.Body); err != nil {
+		return nil, newMeta, err
+	}
+	newMeta.ETag = resp.Header.Get("ETag")
+	newMeta.LastModified = resp.Header.Get("Last-Modified")
+	return data, newMeta, nil
+}
+
+// writeCache replaces the file path by data. The data is written to
+// a temporary file first, so that readers never see half a file.
+func writeCache(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(path), ".gocal-")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
@@ -0,0 +1,60 @@
+package gocal
+
+// Copyright (c) 2014 Stefan Schroeder, NY, 2014-03-10
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file
+//
+// filter.go
+//
+// This file is part of gocal, a PDF calendar generator in Go.
+//
+// https://github.com/StefanSchroeder/Gocal
+//
+
+import (
+	"path"
+	"strings"
+)
+
+// splitTags splits a comma separated list of tags. Spaces around
+// the tags and empty tags are dropped.
+func splitTags(s string) (tags []string) {
+	for _, t := range strings.Split(s, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+// tagged tells if the event has one of the tags. The category
+// counts as a tag. Case does not matter.
+func (ev Event) tagged(tags []string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, ev.Category) && ev.Category != "" {
+			return true
+		}
+		for _, et := range ev.Tags {
+			if strings.EqualFold(t, et) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// excludedSource tells if a source of events is excluded with
+// ExcludeSource. A source is known by its file name or URL, the last
+// element of it, e.g. work.ics, or the name in the file.
+func (g *Calendar) excludedSource(filename, name string) bool {
+	for _, x := range g.OptExcludeSources {
+		switch {
+		case filename != "" && (x == filename || x == path.Base(strings.Replace(filename, "\\", "/", -1))):
+			return true
+		case name != "" && strings.EqualFold(x, name):
+			return true
+		}
+	}
+	return false
+}
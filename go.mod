@@ -3,10 +3,7 @@ module github.com/StefanSchroeder/Gocal
 go 1.16
 
 require (
-	github.com/PuloV/ics-golang v0.0.0-20190808201353-a3394d3bcade
-	github.com/channelmeter/iso8601duration v0.0.0-20150204201828-8da3af7a2a61 // indirect
 	github.com/goodsign/monday v1.0.1
 	github.com/jung-kurt/gofpdf v1.16.2
-	github.com/paulrosania/go-charset v0.0.0-20190326053356-55c9d7a5834c
 	github.com/soniakeys/meeus/v3 v3.0.1
 )
@@ -12,9 +12,14 @@ Copyright (c) 2014 Stefan Schroeder, NY, 2014-03-10
 */
 
 import (
+	"bytes"
 	"fmt"
 	"github.com/jung-kurt/gofpdf"
 	"github.com/soniakeys/meeus/v3/julian"
+	"io"
+	"io/ioutil"
+	"log"
+	"math"
 	"os"
 	"path/filepath"
 	"strings"
@@ -35,6 +40,8 @@ const (
 	DARKGREY = 150
 	// LIGHTGREY is the intensity of grey in neighbor month days.
 	LIGHTGREY = 170
+	// PALEGREY is the shade of multi-day events.
+	PALEGREY = 215
 	// BLACK is black.
 	BLACK = 0
 
@@ -51,54 +58,32 @@ const (
 	FOOTERFONTSIZE   = 12.0
 )
 
-var testedLanguage = map[string]bool{
-	"en_US": true,  // English (United States)
-	"en_GB": true,  // English (United Kingdom)
-	"da_DK": true,  // Danish (Denmark)
-	"nl_BE": true,  // Dutch (Belgium)
-	"nl_NL": true,  // Dutch (Netherlands)
-	"fi_FI": true,  // Finnish (Finland)
-	"fr_FR": true,  // French (France)
-	"fr_CA": true,  // French (Canada)
-	"de_DE": true,  // German (Germany)
-	"hu_HU": true,  // Hungarian (Hungary)
-	"it_IT": true,  // Italian (Italy)
-	"nn_NO": true,  // Norwegian Nynorsk (Norway)
-	"nb_NO": true,  // Norwegian Bokmål (Norway)
-	"pl_PL": false, // Polish (Poland)
-	"pt_PT": true,  // Portuguese (Portugal)
-	"pt_BR": true,  // Portuguese (Brazil)
-	"ro_RO": false, // Romanian (Romania)
-	"ru_RU": false, // Russian (Russia)
-	"es_ES": true,  // Spanish (Spain)
-	"ca_ES": true,  // Catalan (Spain)
-	"sv_SE": true,  // Swedish (Sweden)
-	"tr_TR": false, // Turkish (Turkey)
-	"uk_UA": false, // Ukrainian (Ukraine)
-	"bg_BG": false, // Bulgarian (Bulgaria)
-	"zh_CN": false, // Chinese (Mainland)
-	"zh_TW": false, // Chinese (Taiwan)
-	"zh_HK": false, // Chinese (Hong Kong)
-	"ko_KR": false, // Korean (Korea)
-	"ja_JP": false, // Japanese (Japan)
-	"el_GR": false, // Greek (Greece)
-	"id_ID": true,  // Indonesian (Indonesia)
-	"fr_GP": true,  // French (Guadeloupe)
-	"fr_LU": true,  // French (Luxembourg)
-	"fr_MQ": true,  // French (Martinique)
-	"fr_RE": true,  // French (Reunion)
-	"fr_GF": true,  // French (French Guiana)
-	"cs_CZ": false, // Czech (Czech Republic)
-	"sl_SI": false, // Slovenian (Slovenia)
-	"lt_LT": false, // Lithuanian (Lithuania)
-	"th_TH": false, // Thai (Thailand)
-	"uz_UZ": true,  // Uzbek (Uzbekistan)
+// sundayFirst lists the locales where the week starts on Sunday,
+// saturdayFirst those where it starts on Saturday. All other
+// locales start the week on Monday.
+var sundayFirst = map[string]bool{
+	"en_US": true,
+	"fr_CA": true,
+	"pt_BR": true,
+	"zh_TW": true,
+	"zh_HK": true,
+	"ko_KR": true,
+	"ja_JP": true,
+	"id_ID": true,
+	"th_TH": true,
+}
+
+var saturdayFirst = map[string]bool{
+	"ar_EG": true,
+	"ar_SA": true,
+	"fa_IR": true,
 }
 
 type Calendar struct {
 	WantBeginMonth     int
 	WantEndMonth       int
 	WantYear           int
+	WantEndYear        int
 	OptFont            string
 	OptFooter          string
 	OptOrientation     string
@@ -117,16 +102,47 @@ type Calendar struct {
 	OptPhotos          string
 	OptFontScale       float64
 	OptNocolor         bool
-	EventList          []gDate
+	EventList          []Event
 	OptCutWeekday      int
 	OptFillpattern     string
 	OptYearSpread      int
 	OptICS             []string
 	OptMargin          string
+	OptLogger          *log.Logger
+	OptFirstWeekday    int
+	OptDirection       string
+	OptSecondary       DateProvider
+	OptHolidays        string
+	OptTimezone        string
+	OptShowTimes       bool
+	OptICSData         []icsData
+	OptCacheDir        string
+	OptOffline         bool
+	OptTimeout         time.Duration
+	OptStyle           string
+	OptCategories      []CategoryStyle
+	OptLegend          bool
+	OptShrink          bool
+	OptAgenda          bool
+	OptTags            []string
+	OptExcludeSources  []string
+	OptHideEvents      bool
+	OptTables          []tableSource
+	OptVCards          []string
+	OptExportMoon      bool
+	OptExportHolidays  bool
+	OptSources         []EventSource
+	OptMoonLocation    *time.Location
+	OptMoonTimes       bool
+	OptMoonMode        string
+	OptSouthern        bool
+	OptMoonNames       bool
+	OptYearMoons       bool
 }
 
+// New creates a calendar for the months b to e of the year y.
 func New(b int, e int, y int) *Calendar {
-	return &Calendar{b, e, y,
+	return &Calendar{b, e, y, y,
 		"serif", // OptFont
 		"",      // OptFooter
 		"L",     // OptOrientation P=portrait
@@ -151,38 +167,134 @@ func New(b int, e int, y int) *Calendar {
 		1,       // OptYearSpread
 		nil,     // OptICS
 		"",      // OptMargin
+		nil,     // OptLogger
+		-1,      // OptFirstWeekday, -1 = from locale
+		"",      // OptDirection, "" = from locale
+		nil,     // OptSecondary
+		"",      // OptHolidays
+		"",      // OptTimezone, "" = local time
+		false,   // OptShowTimes
+		nil,     // OptICSData
+		"",      // OptCacheDir, "" = no cache
+		false,   // OptOffline
+		0,       // OptTimeout, 0 = 30 seconds
+		"",      // OptStyle
+		nil,     // OptCategories
+		false,   // OptLegend
+		false,   // OptShrink
+		false,   // OptAgenda
+		nil,     // OptTags
+		nil,     // OptExcludeSources
+		false,   // OptHideEvents
+		nil,     // OptTables
+		nil,     // OptVCards
+		false,   // OptExportMoon
+		false,   // OptExportHolidays
+		nil,     // OptSources
+		nil,     // OptMoonLocation, nil = from OptTimezone
+		false,   // OptMoonTimes
+		"",      // OptMoonMode, "" = symbols
+		false,   // OptSouthern
+		false,   // OptMoonNames
+		false,   // OptYearMoons
 	}
 }
 
-// gDate is a type to store single events
-type gDate struct {
-	Month   time.Month
-	Day     int
-	Text    string
-	Weekday string
-	Image   string
+// NewRange creates a calendar from the month bm in the year by
+// to the month em in the year ey, e.g. for an academic year.
+func NewRange(by int, bm int, ey int, em int) *Calendar {
+	g := New(bm, em, by)
+	g.WantEndYear = ey
+	return g
+}
+
+// Event is a single event. It is on the day Day of the month Month
+// of the year Year, or of every year if Year is 0, or on every
+// Weekday, an English name like "Monday", if that is set.
+type Event struct {
+	Month    time.Month
+	Day      int
+	Text     string // may contain \n and placeholders like {age}
+	Weekday  string
+	Image    string    // file or URL of an image for the day cell
+	Year     int       // 0 means every year
+	Days     int       // more than 1 for events over several days
+	Category string    // name of a CategoryStyle
+	Tags     []string  // for the filter of SetTags
+	Origin   int       // year of the birth or wedding, for {age}
+	Name     string    // for {name}
+	Start    time.Time // moment of a timed event, zero for all-day events
+	End      time.Time // end of a timed event, exclusive
 }
 
 // Gocaldate is an XML type to store single events
 type Gocaldate struct {
-	Date  string `xml:"date,attr"`
-	Text  string `xml:"text,attr"`
-	Image string `xml:"image,attr"`
+	Date     string `xml:"date,attr"`
+	Text     string `xml:"text,attr"`
+	Image    string `xml:"image,attr"`
+	Rule     string `xml:"rule,attr"`  // recurrence rule, e.g. FREQ=MONTHLY;BYDAY=-1FR
+	Start    string `xml:"start,attr"` // first day of the rule, YYYY-MM-DD
+	End      string `xml:"end,attr"`   // last day of a multi-day event
+	From     string `xml:"from,attr"`  // first year of the event
+	Until    string `xml:"until,attr"` // last year of the event
+	Category string `xml:"category,attr"`
+	Tags     string `xml:"tags,attr"`   // comma separated, e.g. family,school
+	Origin   string `xml:"origin,attr"` // year of the birth or wedding, for {age}
+	Name     string `xml:"name,attr"`   // for {name}
 	//	Month   time.Month
 	//	Day     int
 	//	Weekday string
 }
 
-// monthRange stores begin and end month of the year
-type monthRange struct {
-	begin int
-	end   int
+// yearMonth is one month of the calendar range.
+type yearMonth struct {
+	year  int
+	month int
+}
+
+// months returns the list of months from the begin to the end
+// of the calendar range.
+func (g *Calendar) months() (ml []yearMonth, err error) {
+	endYear := g.WantEndYear
+	if endYear == 0 {
+		endYear = g.WantYear
+	}
+	if g.WantBeginMonth < 1 || g.WantBeginMonth > 12 || g.WantEndMonth < 1 || g.WantEndMonth > 12 {
+		return nil, &Error{ErrRange, "", fmt.Errorf("month must be between 1 and 12")}
+	}
+	begin := g.WantYear*12 + g.WantBeginMonth - 1
+	end := endYear*12 + g.WantEndMonth - 1
+	if end < begin {
+		return nil, &Error{ErrRange, "", fmt.Errorf("end %d-%02d is before begin %d-%02d", endYear, g.WantEndMonth, g.WantYear, g.WantBeginMonth)}
+	}
+	for i := begin; i <= end; i++ {
+		ml = append(ml, yearMonth{i / 12, i%12 + 1})
+	}
+	return ml, nil
+}
+
+// rangeTitle is the page title of the year calendars.
+func rangeTitle(ml []yearMonth) string {
+	if ml[0].year == ml[len(ml)-1].year {
+		return fmt.Sprintf("%d", ml[0].year)
+	}
+	return fmt.Sprintf("%d - %d", ml[0].year, ml[len(ml)-1].year)
+}
+
+// rangeBounds returns the first day of the range and the first
+// day after the range.
+func rangeBounds(ml []yearMonth) (from, to time.Time) {
+	last := ml[len(ml)-1]
+	from = time.Date(ml[0].year, time.Month(ml[0].month), 1, 0, 0, 0, 0, time.UTC)
+	to = time.Date(last.year, time.Month(last.month)+1, 1, 0, 0, 0, 0, time.UTC)
+	return from, to
 }
 
 // myPdf is an anonymous struct that allows to define methods on non-local types
 type myPdf struct {
 	*gofpdf.Fpdf
 	moonSize float64
+	south    bool // seen from the southern hemisphere, mirrored
 }
 
 func (pdf myPdf) fullMoon(x, y float64) {
@@ -201,44 +313,111 @@ func (pdf myPdf) lastQuarter(x, y float64) {
 	pdf.Arc(x, y, pdf.moonSize, pdf.moonSize, 0.0, 270.0, 270.0+180.0, "F")
 }
 
-type pdfWriter struct {
-	pdf         *gofpdf.Fpdf
-	fl          *os.File
-	pdfFilename string
+// moon draws the symbol of the moon phase p. In the southern
+// hemisphere the quarters are mirrored.
+func (pdf myPdf) moon(p moonPhase, x, y float64) {
+	switch {
+	case p.name == "Full":
+		pdf.fullMoon(x, y)
+	case p.name == "New":
+		pdf.newMoon(x, y)
+	case (p.name == "First") != pdf.south:
+		pdf.firstQuarter(x, y)
+	default:
+		pdf.lastQuarter(x, y)
+	}
 }
 
-func (pw *pdfWriter) Write(p []byte) (n int, err error) {
-	if pw.pdf.Ok() {
-		return pw.fl.Write(p)
+// litMoon draws the moon with the lit fraction k, lit on the right
+// while waxing in the northern hemisphere. The part in the shadow is
+// filled, up to the terminator, a half ellipse.
+func (pdf myPdf) litMoon(k float64, waxing bool, x, y float64) {
+	r := pdf.moonSize
+	side := 1.0 // the shadow is on the left
+	if waxing == pdf.south {
+		side = -1
 	}
-	return
+	w := r * (1 - 2*k) // the terminator at the height of the center
+	const n = 24
+	pts := make([]gofpdf.PointType, 0, 2*n+2)
+	for j := 0; j <= n; j++ { // the limb, top to bottom
+		a := math.Pi * float64(j) / n
+		pts = append(pts, gofpdf.PointType{X: x - side*r*math.Sin(a), Y: y - r*math.Cos(a)})
+	}
+	for j := n; j >= 0; j-- { // the terminator, bottom to top
+		a := math.Pi * float64(j) / n
+		pts = append(pts, gofpdf.PointType{X: x + side*w*math.Sin(a), Y: y - r*math.Cos(a)})
+	}
+	pdf.Polygon(pts, "F")
+	pdf.Circle(x, y, r, "D")
 }
 
-func (pw *pdfWriter) Close() (err error) {
-	if pw.fl != nil {
-		pw.fl.Close()
-		pw.fl = nil
+// writePDF sends the finished document to w.
+func writePDF(pdf *gofpdf.Fpdf, w io.Writer) error {
+	if err := pdf.Error(); err != nil {
+		return &Error{ErrOutput, "", err}
 	}
-	if pw.pdf.Ok() {
-		fmt.Printf("Generated '%v'.\n", pw.pdfFilename)
-	} else {
-		fmt.Printf("%s\n", pw.pdf.Error())
+	if err := pdf.Output(w); err != nil {
+		return &Error{ErrOutput, "", err}
 	}
-	return
+	return nil
 }
 
-func docWriter(pdf *gofpdf.Fpdf, fname string) *pdfWriter {
-	pw := new(pdfWriter)
-	pw.pdfFilename = fname
-	pw.pdf = pdf
-	if pdf.Ok() {
-		var err error
-		pw.fl, err = os.Create(pw.pdfFilename)
-		if err != nil {
-			pdf.SetErrorf("# Error opening output file '%s'", pw.pdfFilename)
+// writeFile renders a document with the function create and stores it
+// in the file fname. The file is not created if rendering fails.
+func writeFile(fname string, create func(io.Writer) error) error {
+	var buf bytes.Buffer
+	if err := create(&buf); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(fname, buf.Bytes(), 0666); err != nil {
+		return &Error{ErrOutput, fname, err}
+	}
+	return nil
+}
+
+// firstWeekday returns the weekday in the first column of the
+// calendar, which is taken from the locale unless it was set
+// explicitly.
+func (g *Calendar) firstWeekday(locale string) time.Weekday {
+	if g.OptFirstWeekday >= 0 {
+		return time.Weekday(g.OptFirstWeekday % 7)
+	}
+	if sundayFirst[locale] {
+		return time.Sunday
+	}
+	if saturdayFirst[locale] {
+		return time.Saturday
+	}
+	return time.Monday
+}
+
+// weekNumber returns the ISO week number of the week row that
+// begins with the day t. It is the week of the Monday in that row.
+func weekNumber(t time.Time) int {
+	_, weeknr := t.AddDate(0, 0, (8-int(t.Weekday()))%7).ISOWeek()
+	return weeknr
+}
+
+// setupFont adds the calendar font to the document and returns
+// the name of the font family.
+func (g *Calendar) setupFont(pdf *gofpdf.Fpdf) (string, error) {
+	switch g.OptFont {
+	case "mono", "serif", "sans":
+		lang := getLanguage(g.OptLocale)
+		if strings.HasPrefix(lang, "zh_") || lang == "ja_JP" || lang == "ko_KR" ||
+			(g.OptFont != "serif" && (strings.HasPrefix(lang, "ar_") || lang == "fa_IR")) {
+			g.logf("The built-in fonts have no glyphs for %s. Use a TTF font that has them.", lang)
 		}
 	}
-	return pw
+	return processFont(pdf, g.OptFont)
+}
+
+// logf sends a diagnostic message to the logger, if there is one.
+func (g *Calendar) logf(format string, v ...interface{}) {
+	if g.OptLogger != nil {
+		g.OptLogger.Printf(format, v...)
+	}
 }
 
 func (g *Calendar) WantFillMode(s string) bool {
@@ -252,6 +431,17 @@ func (g *Calendar) SetPlain() {
 	g.OptPlain = true
 }
 
+// applyPlain hides the other months, the day of the year, the moon
+// and the week number if SetPlain is set.
+func (g *Calendar) applyPlain() {
+	if g.OptPlain == true {
+		g.SetHideOtherMonth()
+		g.SetHideDOY()
+		g.SetHideMoon()
+		g.SetHideWeek()
+	}
+}
+
 func (g *Calendar) SetHideOtherMonth() {
 	g.OptHideOtherMonths = true
 }
@@ -313,14 +503,44 @@ func (g *Calendar) SetConfig(f string) {
 	g.OptConfig = f
 }
 
+// AddICS adds the events of an ICS file, or of a feed with an
+// http://, https:// or webcal:// URL.
 func (g *Calendar) AddICS(f string) {
 	g.OptICS = append(g.OptICS, f)
 }
 
+// AddICSReader adds the events of the ICS calendar read from r. The
+// data is read at once and kept in memory, so r may be closed when
+// AddICSReader returns. Read errors and malformed data are reported
+// as ErrICS by the Create functions.
+func (g *Calendar) AddICSReader(r io.Reader) {
+	data, err := ioutil.ReadAll(r)
+	g.OptICSData = append(g.OptICSData, icsData{data, err})
+}
+
 func (g *Calendar) AddConfig(f string) {
 	g.OptConfigs = append(g.OptConfigs, f)
 }
 
+// AddCSV adds the events of the CSV file f. The first line names
+// the columns, which m maps to the parts of the events.
+func (g *Calendar) AddCSV(f string, m FieldMap) {
+	g.OptTables = append(g.OptTables, tableSource{f, false, m})
+}
+
+// AddVCard adds the birthdays and anniversaries of the contacts in
+// the vCard file f as yearly events, with the age if the year is
+// known.
+func (g *Calendar) AddVCard(f string) {
+	g.OptVCards = append(g.OptVCards, f)
+}
+
+// AddJSON adds the events of the JSON file f, an array of objects
+// whose fields m maps to the parts of the events.
+func (g *Calendar) AddJSON(f string, m FieldMap) {
+	g.OptTables = append(g.OptTables, tableSource{f, true, m})
+}
+
 func (g *Calendar) SetLocale(f string) {
 	g.OptLocale = f
 }
@@ -346,14 +566,211 @@ func (g *Calendar) SetFillpattern(f string) {
 }
 
 func (g *Calendar) AddEvent(day int, month int, text string, image string) {
-	gcd := gDate{time.Month(month), int(day), text, "", image}
+	gcd := Event{Month: time.Month(month), Day: int(day), Text: text, Image: image, Days: 1}
 	g.EventList = append(g.EventList, gcd)
 }
 
+// AddEvents adds events, which may have a year, a weekday, a
+// category or several days, unlike those of AddEvent.
+func (g *Calendar) AddEvents(events ...Event) {
+	g.EventList = append(g.EventList, events...)
+}
+
+// AddSource adds the events of the source s, e.g. a database. It is
+// asked for the events of the days of the calendar.
+func (g *Calendar) AddSource(s EventSource) {
+	g.OptSources = append(g.OptSources, s)
+}
+
+// AddDatedEvent adds an event that is shown only in the year
+// year, unlike AddEvent, which repeats every year.
+func (g *Calendar) AddDatedEvent(year int, month int, day int, text string, image string) {
+	gcd := Event{Month: time.Month(month), Day: int(day), Text: text, Image: image, Year: year, Days: 1}
+	g.EventList = append(g.EventList, gcd)
+}
+
+// SetFirstWeekday sets the weekday in the first column of the
+// calendar. By default it depends on the locale.
+func (g *Calendar) SetFirstWeekday(wd time.Weekday) {
+	g.OptFirstWeekday = int(wd)
+}
+
+// SetDirection sets the layout direction to "rtl" or "ltr". By
+// default the calendar is laid out from right to left for the
+// Hebrew, Arabic and Persian locales.
+func (g *Calendar) SetDirection(d string) {
+	g.OptDirection = d
+}
+
+// SetSecondary prints the date in another calendar system in the
+// day cells, e.g. SecondaryCalendar("hebrew").
+func (g *Calendar) SetSecondary(p DateProvider) {
+	g.OptSecondary = p
+}
+
+// SetHolidays shows the public holidays of a country or region,
+// e.g. "US", "DE" or "DE-BY". See HolidayRegions for the list.
+func (g *Calendar) SetHolidays(region string) {
+	g.OptHolidays = region
+}
+
+// SetCacheDir keeps a copy of the ICS feeds in the directory dir.
+// A feed is downloaded again only if it has changed (ETag or
+// Last-Modified), and the copy is used if the server cannot be
+// reached.
+func (g *Calendar) SetCacheDir(dir string) {
+	g.OptCacheDir = dir
+}
+
+// SetOffline reads the ICS feeds from the cache only.
+func (g *Calendar) SetOffline() {
+	g.OptOffline = true
+}
+
+// SetTimeout sets the time limit for downloading an ICS feed,
+// 30 seconds by default.
+func (g *Calendar) SetTimeout(d time.Duration) {
+	g.OptTimeout = d
+}
+
+// SetStyle reads the styles of the event categories from the XML
+// file f.
+func (g *Calendar) SetStyle(f string) {
+	g.OptStyle = f
+}
+
+// AddCategoryStyle sets the look of the events of a category. It
+// replaces a style of the same name from the style file.
+func (g *Calendar) AddCategoryStyle(s CategoryStyle) {
+	g.OptCategories = append(g.OptCategories, s)
+}
+
+// SetLegend adds a legend of the category styles below the
+// calendar.
+func (g *Calendar) SetLegend() {
+	g.OptLegend = true
+}
+
+// SetShrink lets the event text of a crowded day get smaller, down
+// to MINEVENTSCALE of the size, before events are left out.
+func (g *Calendar) SetShrink() {
+	g.OptShrink = true
+}
+
+// SetAgenda adds a page after each month that lists the events that
+// did not fit into their day cells.
+func (g *Calendar) SetAgenda() {
+	g.OptAgenda = true
+}
+
+// SetTags shows only the events that have one of the tags or are
+// in a category of that name, e.g. SetTags("family", "holiday").
+func (g *Calendar) SetTags(tags ...string) {
+	g.OptTags = splitTags(strings.Join(tags, ","))
+}
+
+// ExcludeSource leaves out the events of a configuration or ICS
+// file. The source is given by its file name or URL, the last part
+// of it, e.g. "work.ics", or the name in the file: the attribute
+// name of Gocal or the X-WR-CALNAME of an ICS calendar.
+func (g *Calendar) ExcludeSource(name string) {
+	g.OptExcludeSources = append(g.OptExcludeSources, name)
+}
+
+// SetHideEvents leaves out all events of configuration and ICS
+// files and of AddEvent. Holidays are still shown.
+func (g *Calendar) SetHideEvents() {
+	g.OptHideEvents = true
+}
+
+// SetMoonLocation sets the time zone in which the days and times
+// of the moon phases are computed. By default it is the time zone
+// of SetTimezone, or UTC.
+func (g *Calendar) SetMoonLocation(loc *time.Location) {
+	g.OptMoonLocation = loc
+}
+
+// SetMoonTimes prints the time of the moon phases, e.g. 14:22,
+// below the moon in the month calendar.
+func (g *Calendar) SetMoonTimes() {
+	g.OptMoonTimes = true
+}
+
+// SetMoonMode sets how the moon is drawn: "quarters" draws the lit
+// part of the moon on the days of the phases, "daily" on every day.
+// By default the phases are drawn as symbols.
+func (g *Calendar) SetMoonMode(m string) {
+	g.OptMoonMode = m
+}
+
+// SetSouthern draws the moon as seen from the southern hemisphere,
+// where it is lit from the left while waxing.
+func (g *Calendar) SetSouthern() {
+	g.OptSouthern = true
+}
+
+// SetMoonNames prints the name of the moon phases, e.g. Full moon,
+// in the language of the calendar below the moon in the month
+// calendar.
+func (g *Calendar) SetMoonNames() {
+	g.OptMoonNames = true
+}
+
+// SetYearMoons draws the moon phases in the year calendars too.
+func (g *Calendar) SetYearMoons() {
+	g.OptYearMoons = true
+}
+
+// SetExportMoon adds the moon phases to the events of CreateICS.
+func (g *Calendar) SetExportMoon() {
+	g.OptExportMoon = true
+}
+
+// SetExportHolidays adds the holidays of SetHolidays to the events
+// of CreateICS.
+func (g *Calendar) SetExportHolidays() {
+	g.OptExportHolidays = true
+}
+
+// SetTimezone sets the time zone, e.g. "Europe/Berlin", in which
+// the events of ICS files and the moon phases are shown. By default
+// it is the local time zone.
+func (g *Calendar) SetTimezone(tz string) {
+	g.OptTimezone = tz
+}
+
+// SetShowTimes prints the start time before the text of the
+// events of ICS files that are not all-day events.
+func (g *Calendar) SetShowTimes() {
+	g.OptShowTimes = true
+}
+
+// location returns the time zone of the calendar.
+func (g *Calendar) location() (*time.Location, error) {
+	if g.OptTimezone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(g.OptTimezone)
+	if err != nil {
+		return nil, &Error{ErrTimezone, g.OptTimezone, err}
+	}
+	return loc, nil
+}
+
 func (g *Calendar) SetPaperformat(f string) {
 	g.OptPaperformat = f
 }
 
+// SetLogger sets the logger that receives diagnostic messages.
+// By default the library is silent.
+func (g *Calendar) SetLogger(l *log.Logger) {
+	g.OptLogger = l
+}
+
+// WantFill tells if a cell shall be filled. The digits and the letters
+// S and s of the fill pattern select weekdays, the other letters select
+// rows i and columns j as they appear on the page, i.e. counted from
+// the first weekday of the calendar.
 func (g *Calendar) WantFill(i int, j int, wd time.Weekday) bool {
 
 	if wd == time.Monday && g.WantFillMode("1") {
@@ -428,37 +845,80 @@ func getLanguage(inLanguage string) (outLanguage string) {
 		outLanguage = inLanguage
 	}
 
+	// Strip the encoding and modifier, as in de_DE.UTF-8.
+	if i := strings.IndexAny(outLanguage, ".@"); i != -1 {
+		outLanguage = outLanguage[:i]
+	}
+
 	// if we don't know that language, fall back to en.
-	if testedLanguage[outLanguage] != true {
+	if !knownLanguage(outLanguage) {
 		outLanguage = "en_US"
 	}
 	return
 }
 
-func (g *Calendar) AddWallpaper(pdf *gofpdf.Fpdf, fontTempdir string, PAGEWIDTH float64, PAGEHEIGHT float64) {
-	wallpaperFilename := g.OptWallpaper
-	if strings.HasPrefix(wallpaperFilename, "http://") {
-		wallpaperFilename = downloadFile(g.OptWallpaper, fontTempdir)
+func (g *Calendar) AddWallpaper(pdf *gofpdf.Fpdf, PAGEWIDTH float64, PAGEHEIGHT float64) error {
+	if strings.HasPrefix(g.OptWallpaper, "http://") {
+		if err := downloadImage(pdf, g.OptWallpaper); err != nil {
+			return &Error{ErrPhoto, g.OptWallpaper, err}
+		}
+	}
+	return addImage(pdf, g.OptWallpaper, 0, 0, PAGEWIDTH, PAGEHEIGHT)
+}
+
+// addImage puts an image on the page and reports a broken
+// or missing image file as ErrPhoto.
+func addImage(pdf *gofpdf.Fpdf, fname string, x, y, w, h float64) error {
+	pdf.Image(fname, x, y, w, h, false, "", 0, "")
+	if err := pdf.Error(); err != nil {
+		return &Error{ErrPhoto, fname, err}
 	}
-	pdf.Image(wallpaperFilename, 0, 0, PAGEWIDTH, PAGEHEIGHT, false, "", 0, "")
+	return nil
+}
+
+// CreateYearCalendarInverse writes the yearB calendar to the file fn.
+func (g *Calendar) CreateYearCalendarInverse(fn string) error {
+	return writeFile(fn, g.CreateYearCalendarInverseTo)
 }
 
-func (g *Calendar) CreateYearCalendarInverse(fn string) {
+// CreateYearCalendarInverseTo writes the yearB calendar to w.
+func (g *Calendar) CreateYearCalendarInverseTo(w io.Writer) error {
 
-	var fontTempdir string
 	var fontScale = g.OptFontScale
-	var calFont = g.OptFont
+
+	g.applyPlain()
 
 	if g.OptSmall == true {
 		fontScale = 0.75
 	}
 
-	wantyear := g.WantYear
-
-	calFont, fontTempdir = processFont(calFont)
+	monthList, err := g.months()
+	if err != nil {
+		return err
+	}
+	rangeFrom, rangeTo := rangeBounds(monthList)
+	eventList, holidayDays, err := g.events(rangeFrom, rangeTo)
+	if err != nil {
+		return err
+	}
+	store := newEventStore(eventList)
+	// Multi-day events are shaded, overlapping ones side by side.
+	spans, lanes := spanLanes(store.multiDay, rangeFrom, rangeTo)
+	styles, order, err := g.styles()
+	if err != nil {
+		return err
+	}
+	days := dayStyles(store, styles, rangeFrom, rangeTo)
+	moons, err := g.moons(rangeFrom, rangeTo)
+	if err != nil {
+		return err
+	}
 
-	pdf := gofpdf.New(g.OptOrientation, "mm", g.OptPaperformat, fontTempdir)
-	pdf.AddFont(calFont, "", calFont+".json")
+	pdf := gofpdf.New(g.OptOrientation, "mm", g.OptPaperformat, "")
+	calFont, err := g.setupFont(pdf)
+	if err != nil {
+		return err
+	}
 
 	pdf.SetFillColor(LIGHTGREY, LIGHTGREY, LIGHTGREY)
 	pdf.SetMargins(10.0, 5.0, 10.0)
@@ -474,43 +934,73 @@ func (g *Calendar) CreateYearCalendarInverse(fn string) {
 	currentLanguage := getLanguage(g.OptLocale)
 	localizedWeekdayNames := getLocalizedWeekdayNames(currentLanguage, 2)
 	localizedMonthNames := getLocalizedMonthNames(currentLanguage)
+	firstWeekday := g.firstWeekday(currentLanguage)
+	rtl := g.rtl(currentLanguage)
+
+	// The spread divides the range into pages. A spread of 1
+	// puts twelve months on one page.
+	monthOnePage := (len(monthList) + g.OptYearSpread - 1) / g.OptYearSpread
+	pages := (len(monthList) + monthOnePage - 1) / monthOnePage
+	cw = cw * 12 / float64(monthOnePage)
+	dw := cw * 0.5 * float64(monthOnePage) / 12 // width of the day column
+	left := pdf.GetX()
+
+	// columnX returns the position of the column of the k-th month
+	// on the page, or of the day column for k = -1.
+	columnX := func(k int, n int) float64 {
+		if !rtl {
+			return left + dw + float64(k)*cw
+		}
+		if k < 0 {
+			return left + float64(n)*cw
+		}
+		return left + float64(n-1-k)*cw
+	}
 
-	monthFracture := g.OptYearSpread
-	cw = cw * float64(monthFracture)
-	monthOnePage := 12 / monthFracture
-
-	for pageCount := 0; pageCount < monthFracture; pageCount++ {
+	for pageCount := 0; pageCount < pages; pageCount++ {
+		pageMonths := monthList[pageCount*monthOnePage:]
+		if len(pageMonths) > monthOnePage {
+			pageMonths = pageMonths[:monthOnePage]
+		}
 		pdf.AddPage()
 
 		pdf.SetTextColor(BLACK, BLACK, BLACK)
 		pdf.SetFont(calFont, "", HEADERFONTSIZE*fontScale)
-		pdf.CellFormat(PAGEWIDTH-MARGIN, MARGIN, fmt.Sprintf("%d", wantyear), "", 0, "C", false, 0, "")
+		pdf.CellFormat(PAGEWIDTH-MARGIN, MARGIN, rangeTitle(monthList), "", 0, "C", false, 0, "")
 
 		if g.OptWallpaper != "" {
-			g.AddWallpaper(pdf, fontTempdir, PAGEWIDTH, PAGEHEIGHT)
+			if err := g.AddWallpaper(pdf, PAGEWIDTH, PAGEHEIGHT); err != nil {
+				return err
+			}
 		}
 
 		pdf.Ln(-1)
 
+		n := len(pageMonths)
 		pdf.SetTextColor(BLACK, BLACK, BLACK)
-		pdf.CellFormat(cw*0.5/float64(monthFracture), ch*0.75, "", "1", 0, "C", false, 0, "")
+		pdf.SetX(columnX(-1, n))
+		pdf.CellFormat(dw, ch*0.75, "", "1", 0, "C", false, 0, "")
 
 		pdf.SetTextColor(BLACK, BLACK, BLACK)
 		pdf.SetFont(calFont, "", FOOTERFONTSIZE*fontScale*0.8)
 		pdf.SetFillColor(LIGHTGREY, LIGHTGREY, LIGHTGREY)
-		for mo := pageCount*monthOnePage + 1; mo <= pageCount*monthOnePage+monthOnePage; mo++ {
-			pdf.CellFormat(cw, ch*0.75, fmt.Sprintf("%s", localizedMonthNames[mo]), "1", 0, "C", false, 0, "")
+		for k, ym := range pageMonths {
+			pdf.SetX(columnX(k, n))
+			pdf.CellFormat(cw, ch*0.75, visual(localizedMonthNames[ym.month]), "1", 0, "C", false, 0, "")
 		}
 		pdf.Ln(-1)
 		pdf.SetFont(calFont, "", MONTHDAYFONTSIZE*fontScale*0.25)
 		for i := 1; i <= 31; i++ {
 			pdf.SetTextColor(BLACK, BLACK, BLACK)
-			pdf.CellFormat(cw*0.5/float64(monthFracture), ch*0.9, fmt.Sprintf("%d", i), "1", 0, "C", false, 0, "")
-			for j := pageCount*monthOnePage + 1; j <= pageCount*monthOnePage+monthOnePage; j++ {
-				tDay := time.Date(wantyear, time.Month(j), i, 0, 0, 0, 0, time.UTC)
-				wd := localizedWeekdayNames[(tDay.Weekday()+1)%7]
-
-				if (tDay.Weekday() == time.Saturday || tDay.Weekday() == time.Sunday) && !g.OptNocolor {
+			pdf.SetX(columnX(-1, n))
+			pdf.CellFormat(dw, ch*0.9, fmt.Sprintf("%d", i), "1", 0, "C", false, 0, "")
+			for k, ym := range pageMonths {
+				pdf.SetX(columnX(k, n))
+				j := ym.month
+				tDay := time.Date(ym.year, time.Month(j), i, 0, 0, 0, 0, time.UTC)
+				wd := visual(localizedWeekdayNames[(tDay.Weekday()+1)%7])
+
+				if (tDay.Weekday() == time.Saturday || tDay.Weekday() == time.Sunday || holidayDays[tDay.Format("2006-01-02")]) && !g.OptNocolor {
 					pdf.SetTextColor(255, 0, 0) // RED
 				} else {
 					pdf.SetTextColor(BLACK, BLACK, BLACK)
@@ -518,26 +1008,57 @@ func (g *Calendar) CreateYearCalendarInverse(fn string) {
 
 				_, readbackMonth, _ := tDay.Date()
 				if int(readbackMonth) == int(j) {
+					ds := days[tDay.Format("2006-01-02")]
+					if tint := tintOf(ds); tint != nil {
+						x, y := pdf.GetXY()
+						setFill(pdf, tint, LIGHTGREY)
+						pdf.Rect(x, y, cw, ch*0.9, "F")
+						pdf.SetFillColor(LIGHTGREY, LIGHTGREY, LIGHTGREY)
+					}
+					on := spansOn(spans, tDay)
+					for _, s := range on {
+						x, y := pdf.GetXY()
+						lane := mirrorColumn(s.lane, lanes, rtl)
+						setFill(pdf, styles[strings.ToLower(s.ev.Category)].tint, PALEGREY)
+						pdf.Rect(x+float64(lane)*cw/float64(lanes), y, cw/float64(lanes), ch*0.9, "F")
+						pdf.SetFillColor(LIGHTGREY, LIGHTGREY, LIGHTGREY)
+					}
 
 					// Day of year, lower right
 					if g.OptHideDOY == false && int(tDay.Month()) == j {
-						doy := julian.DayOfYearGregorian(wantyear, int(time.Month(j)), int(tDay.Day()))
+						doy := julian.DayOfYearGregorian(ym.year, int(time.Month(j)), int(tDay.Day()))
 						pdf.SetFont(calFont, "", DOYFONTSIZE*fontScale*0.5)
-						pdf.CellFormat(cw, ch*0.9, fmt.Sprintf("%d", doy), "1", 0, "BR", false, 0, "")
+						pdf.CellFormat(cw, ch*0.9, fmt.Sprintf("%d", doy), "1", 0, mirrorAlign("BR", rtl), false, 0, "")
 						pdf.SetX(pdf.GetX() - cw) // reset
 					}
 					// Add week number, lower left
-					if tDay.Weekday() == time.Monday && g.OptHideWeek == false {
+					if tDay.Weekday() == firstWeekday && g.OptHideWeek == false {
 						pdf.SetFont(calFont, "", WEEKFONTSIZE*0.5*fontScale)
-						_, weeknr := tDay.ISOWeek()
-						pdf.CellFormat(cw, ch*0.9, fmt.Sprintf("W %d", weeknr), "1", 0, "BL", false, 0, "")
+						pdf.CellFormat(cw, ch*0.9, fmt.Sprintf("W %d", weekNumber(tDay)), "1", 0, mirrorAlign("BL", rtl), false, 0, "")
+						pdf.SetX(pdf.GetX() - cw) // reset
+					}
+					// Date in the secondary calendar, upper right
+					if g.OptSecondary != nil {
+						pdf.SetFont(calFont, "", DOYFONTSIZE*fontScale*0.5)
+						pdf.CellFormat(cw, ch*0.9, visual(g.OptSecondary.Format(tDay, true)), "1", 0, mirrorAlign("TR", rtl), false, 0, "")
 						pdf.SetX(pdf.GetX() - cw) // reset
 					}
 
-					fillBox := g.WantFill(i, j, tDay.Weekday())
+					fillBox := g.WantFill(i, j, tDay.Weekday()) && len(on) == 0 && tintOf(ds) == nil
 
 					pdf.SetFont(calFont, "", MONTHDAYFONTSIZE*fontScale*0.25)
-					pdf.CellFormat(cw, ch*0.9, fmt.Sprintf("%s", wd), "1", 0, "TL", fillBox, 0, "")
+					pdf.CellFormat(cw, ch*0.9, fmt.Sprintf("%s", wd), "1", 0, mirrorAlign("TL", rtl), fillBox, 0, "")
+					// Dots of the categories, at the bottom
+					x, y := pdf.GetXY()
+					drawDots(pdf, ds, x-cw*0.5, y+ch*0.75, ch*0.06)
+					// Moon phase, on the right
+					if g.OptYearMoons && !g.OptHideMoon {
+						moonX := x - cw*0.15
+						if rtl {
+							moonX = x - cw*0.85
+						}
+						moons.draw(pdf, tDay, moonX, y+ch*0.45, ch*0.12)
+					}
 				} else {
 					// empty cell to skip ahead
 					pdf.CellFormat(cw, ch*0.9, "", "1", 0, "TL", false, 0, "")
@@ -547,9 +1068,17 @@ func (g *Calendar) CreateYearCalendarInverse(fn string) {
 		}
 
 		pdf.Ln(-1)
+		if g.OptLegend {
+			legendX := left
+			if rtl {
+				legendX = PAGEWIDTH - left
+			}
+			drawLegend(pdf, styles, order, legendX, pdf.GetY()+FOOTERFONTSIZE*fontScale/3.0, FOOTERFONTSIZE*fontScale*0.8, rtl)
+		}
 		pdf.SetTextColor(DARKGREY, DARKGREY, DARKGREY)
 		pdf.SetFont(calFont, "", FOOTERFONTSIZE*fontScale)
-		pdf.Text(0.50*PAGEWIDTH-pdf.GetStringWidth(g.OptFooter)*0.5, 0.95*PAGEHEIGHT, fmt.Sprintf("%s", g.OptFooter))
+		footer := visual(g.OptFooter)
+		pdf.Text(0.50*PAGEWIDTH-pdf.GetStringWidth(footer)*0.5, 0.95*PAGEHEIGHT, footer)
 
 
 		pdf.TransformBegin()// TODO Hardcoded A4 portrait
@@ -560,26 +1089,52 @@ func (g *Calendar) CreateYearCalendarInverse(fn string) {
 		pdf.TransformEnd()
 	}
 
-	pdf.OutputAndClose(docWriter(pdf, fn))
-	removeTempdir(fontTempdir)
+	return writePDF(pdf, w)
+}
+
+// CreateYearCalendar writes the yearA calendar to the file fn.
+func (g *Calendar) CreateYearCalendar(fn string) error {
+	return writeFile(fn, g.CreateYearCalendarTo)
 }
 
-func (g *Calendar) CreateYearCalendar(fn string) {
+// CreateYearCalendarTo writes the yearA calendar to w.
+func (g *Calendar) CreateYearCalendarTo(w io.Writer) error {
 
-	var fontTempdir string
 	var fontScale = g.OptFontScale
-	var calFont = g.OptFont
+
+	g.applyPlain()
 
 	if g.OptSmall == true {
 		fontScale = 0.75
 	}
 
-	wantyear := g.WantYear
-
-	calFont, fontTempdir = processFont(calFont)
+	monthList, err := g.months()
+	if err != nil {
+		return err
+	}
+	rangeFrom, rangeTo := rangeBounds(monthList)
+	eventList, holidayDays, err := g.events(rangeFrom, rangeTo)
+	if err != nil {
+		return err
+	}
+	store := newEventStore(eventList)
+	// Multi-day events are shaded, overlapping ones side by side.
+	spans, lanes := spanLanes(store.multiDay, rangeFrom, rangeTo)
+	styles, order, err := g.styles()
+	if err != nil {
+		return err
+	}
+	days := dayStyles(store, styles, rangeFrom, rangeTo)
+	moons, err := g.moons(rangeFrom, rangeTo)
+	if err != nil {
+		return err
+	}
 
-	pdf := gofpdf.New(g.OptOrientation, "mm", g.OptPaperformat, fontTempdir)
-	pdf.AddFont(calFont, "", calFont+".json")
+	pdf := gofpdf.New(g.OptOrientation, "mm", g.OptPaperformat, "")
+	calFont, err := g.setupFont(pdf)
+	if err != nil {
+		return err
+	}
 
 	pdf.SetFillColor(LIGHTGREY, LIGHTGREY, LIGHTGREY)
 	pdf.SetMargins(10.0, 5.0, 10.0)
@@ -593,35 +1148,54 @@ func (g *Calendar) CreateYearCalendar(fn string) {
 	currentLanguage := getLanguage(g.OptLocale)
 	localizedWeekdayNames := getLocalizedWeekdayNames(currentLanguage, 2)
 	localizedMonthNames := getLocalizedMonthNames(currentLanguage)
+	firstWeekday := g.firstWeekday(currentLanguage)
+	rtl := g.rtl(currentLanguage)
 
-	monthFracture := g.OptYearSpread
-	monthOnePage := 12 / monthFracture
+	// The spread divides the range into pages. A spread of 1
+	// puts twelve months on one page.
+	monthOnePage := (len(monthList) + g.OptYearSpread - 1) / g.OptYearSpread
+	pages := (len(monthList) + monthOnePage - 1) / monthOnePage
 
 	cw := (PAGEWIDTH - 2*MARGIN) / 32
 	ch := (PAGEHEIGHT - 2*MARGIN) / 14
-	ch = ch * float64(monthFracture)
-	for pageCount := 0; pageCount < monthFracture; pageCount++ {
+	ch = ch * 12 / float64(monthOnePage)
+	left := pdf.GetX()
+
+	// columnX returns the position of column j, where column 0
+	// holds the month names and the columns 1 to 31 the days.
+	columnX := func(j int) float64 {
+		return left + float64(mirrorColumn(j, 32, rtl))*cw
+	}
+	for pageCount := 0; pageCount < pages; pageCount++ {
+		pageMonths := monthList[pageCount*monthOnePage:]
+		if len(pageMonths) > monthOnePage {
+			pageMonths = pageMonths[:monthOnePage]
+		}
 		pdf.AddPage()
 		pdf.SetTextColor(BLACK, BLACK, BLACK)
 
 		if g.OptWallpaper != "" {
-			g.AddWallpaper(pdf, fontTempdir, PAGEWIDTH, PAGEHEIGHT)
+			if err := g.AddWallpaper(pdf, PAGEWIDTH, PAGEHEIGHT); err != nil {
+				return err
+			}
 		}
 
 		pdf.SetFont(calFont, "", MONTHDAYFONTSIZE*fontScale)
-		pdf.CellFormat(PAGEWIDTH-MARGIN, MARGIN, fmt.Sprintf("%d", wantyear), "", 0, "C", false, 0, "")
+		pdf.CellFormat(PAGEWIDTH-MARGIN, MARGIN, rangeTitle(monthList), "", 0, "C", false, 0, "")
 		pdf.Ln(-1)
 
 		pdf.SetTextColor(BLACK, BLACK, BLACK)
 		monthTable := func(mymonth int, myyear int) {
 			var day int64 = 1
 
+			pdf.SetX(columnX(0))
 			pdf.CellFormat(cw, ch, "", "1", 0, "C", false, 0, "")
 			for j := 1; j < 32; j++ {
+				pdf.SetX(columnX(j))
 				pdf.SetFont(calFont, "", MONTHDAYFONTSIZE*fontScale*0.25)
 
 				tDay := time.Date(myyear, time.Month(mymonth), j, 0, 0, 0, 0, time.UTC)
-				if (tDay.Weekday() == time.Saturday || tDay.Weekday() == time.Sunday) && !g.OptNocolor {
+				if (tDay.Weekday() == time.Saturday || tDay.Weekday() == time.Sunday || holidayDays[tDay.Format("2006-01-02")]) && !g.OptNocolor {
 					pdf.SetTextColor(255, 0, 0) // RED
 				} else {
 					pdf.SetTextColor(BLACK, BLACK, BLACK)
@@ -631,26 +1205,56 @@ func (g *Calendar) CreateYearCalendar(fn string) {
 				// the month that arrived.
 				_, readbackMonth, _ := tDay.Date()
 				if int(readbackMonth) == mymonth {
+					ds := days[tDay.Format("2006-01-02")]
+					if tint := tintOf(ds); tint != nil {
+						x, y := pdf.GetXY()
+						setFill(pdf, tint, LIGHTGREY)
+						pdf.Rect(x, y, cw, ch, "F")
+						pdf.SetFillColor(LIGHTGREY, LIGHTGREY, LIGHTGREY)
+					}
+					on := spansOn(spans, tDay)
+					for _, s := range on {
+						x, y := pdf.GetXY()
+						setFill(pdf, styles[strings.ToLower(s.ev.Category)].tint, PALEGREY)
+						pdf.Rect(x, y+float64(s.lane)*ch/float64(lanes), cw, ch/float64(lanes), "F")
+						pdf.SetFillColor(LIGHTGREY, LIGHTGREY, LIGHTGREY)
+					}
 
 					// Day of year, lower right
-					if g.OptHideDOY == false && int(tDay.Month()) == mymonth && tDay.Weekday() != time.Monday {
-						doy := julian.DayOfYearGregorian(wantyear, int(mymonth), int(tDay.Day()))
+					if g.OptHideDOY == false && int(tDay.Month()) == mymonth && tDay.Weekday() != firstWeekday {
+						doy := julian.DayOfYearGregorian(myyear, int(mymonth), int(tDay.Day()))
 						pdf.SetFont(calFont, "", DOYFONTSIZE*fontScale*0.5)
-						pdf.CellFormat(cw, ch, fmt.Sprintf("%d", doy), "1", 0, "BR", false, 0, "")
+						pdf.CellFormat(cw, ch, fmt.Sprintf("%d", doy), "1", 0, mirrorAlign("BR", rtl), false, 0, "")
 						pdf.SetX(pdf.GetX() - cw) // reset
 					}
 					// Add week number, lower left
-					if tDay.Weekday() == time.Monday && g.OptHideWeek == false {
+					if tDay.Weekday() == firstWeekday && g.OptHideWeek == false {
 						pdf.SetFont(calFont, "", WEEKFONTSIZE*0.5*fontScale)
-						_, weeknr := tDay.ISOWeek()
-						pdf.CellFormat(cw, ch, fmt.Sprintf("W %d", weeknr), "1", 0, "BL", false, 0, "")
+						pdf.CellFormat(cw, ch, fmt.Sprintf("W %d", weekNumber(tDay)), "1", 0, mirrorAlign("BL", rtl), false, 0, "")
+						pdf.SetX(pdf.GetX() - cw) // reset
+					}
+					// Date in the secondary calendar, in the middle
+					if g.OptSecondary != nil {
+						pdf.SetFont(calFont, "", DOYFONTSIZE*fontScale*0.5)
+						pdf.CellFormat(cw, ch, visual(g.OptSecondary.Format(tDay, true)), "1", 0, mirrorAlign("LM", rtl), false, 0, "")
 						pdf.SetX(pdf.GetX() - cw) // reset
 					}
 
-					fillBox := g.WantFill(mymonth, j, tDay.Weekday())
+					fillBox := g.WantFill(mymonth, j, tDay.Weekday()) && len(on) == 0 && tintOf(ds) == nil
 
 					pdf.SetFont(calFont, "", MONTHDAYFONTSIZE*fontScale*0.25)
-					pdf.CellFormat(cw, ch, fmt.Sprintf("%s", localizedWeekdayNames[(tDay.Weekday()+1)%7]), "1", 0, "TL", fillBox, 0, "")
+					pdf.CellFormat(cw, ch, visual(localizedWeekdayNames[(tDay.Weekday()+1)%7]), "1", 0, mirrorAlign("TL", rtl), fillBox, 0, "")
+					// Dots of the categories, in the middle
+					x, y := pdf.GetXY()
+					drawDots(pdf, ds, x-cw*0.5, y+ch*0.6, cw*0.06)
+					// Moon phase, upper right
+					if g.OptYearMoons && !g.OptHideMoon {
+						moonX := x - cw*0.25
+						if rtl {
+							moonX = x - cw*0.75
+						}
+						moons.draw(pdf, tDay, moonX, y+cw*0.25, cw*0.12)
+					}
 					day++
 				}
 			}
@@ -658,34 +1262,44 @@ func (g *Calendar) CreateYearCalendar(fn string) {
 
 		var day int64 = 1
 
-		// The header cells shall not scale with the monthFracture. Undo it.
-		var ch_header = ch / float64(monthFracture) * 0.3
+		// The header cells shall not scale with the spread. Undo it.
+		var ch_header = ch * float64(monthOnePage) / 12 * 0.3
+		pdf.SetX(columnX(0))
 		pdf.CellFormat(cw, ch_header, "", "1", 0, "C", false, 0, "")
 
 		// top row: 1..31
 		for j := 0; j < 31; j++ {
+			pdf.SetX(columnX(j + 1))
 			pdf.SetFont(calFont, "", MONTHDAYFONTSIZE*fontScale*0.25)
 			pdf.CellFormat(cw, ch_header, fmt.Sprintf("%d", day), "1", 0, "C", false, 0, "")
 			day++
 		}
 		pdf.Ln(-1)
 
-		//for mo := 1; mo <= totalMonth; mo++ {
-		for mo := pageCount*monthOnePage + 1; mo <= pageCount*monthOnePage+monthOnePage; mo++ {
+		for _, ym := range pageMonths {
 			pdf.SetTextColor(BLACK, BLACK, BLACK)
 			pdf.SetFont(calFont, "", FOOTERFONTSIZE*fontScale*0.8)
 			pdf.TransformBegin()
-			x, y := pdf.GetXY()
+			_, y := pdf.GetXY()
+			x := columnX(0)
 			pdf.TransformRotate(90, x+cw-CELLMARGIN, y+ch-CELLMARGIN)
-			pdf.Text(x+cw-CELLMARGIN, y+ch-CELLMARGIN*2, localizedMonthNames[mo])
+			pdf.Text(x+cw-CELLMARGIN, y+ch-CELLMARGIN*2, visual(localizedMonthNames[ym.month]))
 			pdf.TransformEnd()
-			monthTable(mo, wantyear)
+			monthTable(ym.month, ym.year)
 			pdf.Ln(-1)
 		}
 		pdf.Ln(-1)
+		if g.OptLegend {
+			legendX := left
+			if rtl {
+				legendX = PAGEWIDTH - left
+			}
+			drawLegend(pdf, styles, order, legendX, pdf.GetY()+FOOTERFONTSIZE*fontScale/3.0, FOOTERFONTSIZE*fontScale*0.8, rtl)
+		}
 		pdf.SetTextColor(DARKGREY, DARKGREY, DARKGREY)
 		pdf.SetFont(calFont, "", FOOTERFONTSIZE*fontScale)
-		pdf.Text(0.50*PAGEWIDTH-pdf.GetStringWidth(g.OptFooter)*0.5, 0.95*PAGEHEIGHT, fmt.Sprintf("%s", g.OptFooter))
+		footer := visual(g.OptFooter)
+		pdf.Text(0.50*PAGEWIDTH-pdf.GetStringWidth(footer)*0.5, 0.95*PAGEHEIGHT, footer)
 
 		pdf.TransformBegin()// TODO Hardcoded A4 portrait
 		ctrX := 210.0 * 0.96
@@ -695,48 +1309,115 @@ func (g *Calendar) CreateYearCalendar(fn string) {
 		pdf.TransformEnd()
 	}
 
-	pdf.OutputAndClose(docWriter(pdf, fn))
-	removeTempdir(fontTempdir)
+	return writePDF(pdf, w)
 }
 
-func getPhotolist(in string, temp string) (out [12]string) {
+func getPhotolist(in string, pdf *gofpdf.Fpdf) (out []string, err error) {
 	if in != "" {
-		for i := 0; i < 12; i++ {
-			photoname := in
-			if strings.HasPrefix(photoname, "http://") {
-				photoname = downloadFile(photoname, temp)
+		if strings.HasPrefix(in, "http://") {
+			if err = downloadImage(pdf, in); err != nil {
+				return nil, &Error{ErrPhoto, in, err}
 			}
-			out[i] = photoname
 		}
+		out = []string{in}
 	}
-	return out
+	return out, nil
 }
 
-func getPhotoslist(in string) (out [12]string) {
+func getPhotoslist(in string) (out []string, err error) {
 	if in != "" {
-		fileList, err := filepath.Glob(in + string(os.PathSeparator) + "*")
-		if err == nil {
-			for i := 0; i < 12; i++ {
-				out[i] = fileList[i%len(fileList)]
+		out, err = filepath.Glob(in + string(os.PathSeparator) + "*")
+		if err != nil {
+			return nil, &Error{ErrPhoto, in, err}
+		}
+		if len(out) == 0 {
+			return nil, &Error{ErrPhoto, in, fmt.Errorf("no photos found")}
+		}
+	}
+	return out, nil
+}
+
+// events returns the events of the event files, the ICS files,
+// AddEvent and the holidays for the days between from and to,
+// and the days that are holidays.
+func (g *Calendar) events(from, to time.Time) (eventList []Event, holidayDays map[string]bool, err error) {
+	eventList, err = g.ownEvents(from, to)
+	if err != nil {
+		return nil, nil, err
+	}
+	holidayList, holidayDays, err := g.holidayEvents(from, to)
+	if err != nil {
+		return nil, nil, err
+	}
+	eventList = g.tagFilter(append(eventList, holidayList...))
+	// Holidays that are filtered out are ordinary days.
+	if len(g.OptTags) > 0 && !(Event{Category: "holiday"}).tagged(g.OptTags) {
+		holidayDays = map[string]bool{}
+	}
+	return eventList, holidayDays, nil
+}
+
+// ownEvents returns the events of all sources but the holidays
+// for the days between from and to.
+func (g *Calendar) ownEvents(from, to time.Time) (eventList []Event, err error) {
+	if g.OptHideEvents {
+		return nil, nil
+	}
+	for _, src := range g.sources() {
+		events, err := src.Events(from, to)
+		if err != nil {
+			return nil, err
+		}
+		for _, ev := range events {
+			if ev.Days < 1 {
+				ev.Days = 1
 			}
-		} else {
-			fmt.Printf("# There is an error in your path to photos: %v\n", err)
+			if ev.Days > 1 && ev.Year == 0 {
+				eventList = append(eventList, yearlySpans(ev, from, to)...)
+				continue
+			}
+			eventList = append(eventList, ev)
+		}
+	}
+	return eventList, nil
+}
+
+// eventText returns the text of the event ev on the day t, with the
+// start time before it for timed events if SetShowTimes is set.
+func (g *Calendar) eventText(ev Event, t time.Time, weekday string) string {
+	text := ev.expand(t, weekday)
+	if g.OptShowTimes && !ev.Start.IsZero() {
+		text = ev.Start.Format("15:04") + " " + text
+	}
+	return text
+}
+
+// tagFilter returns the events that have one of the tags of
+// SetTags, or all without tags.
+func (g *Calendar) tagFilter(events []Event) []Event {
+	if len(g.OptTags) == 0 {
+		return events
+	}
+	var tagged []Event
+	for _, ev := range events {
+		if ev.tagged(g.OptTags) {
+			tagged = append(tagged, ev)
 		}
 	}
-	return out
+	return tagged
+}
+
+// CreateCalendar writes the monthly calendar to the file fn.
+func (g *Calendar) CreateCalendar(fn string) error {
+	return writeFile(fn, g.CreateCalendarTo)
 }
 
-func (g *Calendar) CreateCalendar(fn string) {
+// CreateCalendarTo writes the monthly calendar to w.
+func (g *Calendar) CreateCalendarTo(w io.Writer) error {
 
-	var fontTempdir string
 	var fontScale = g.OptFontScale
 
-	if g.OptPlain == true {
-		g.SetHideOtherMonth()
-		g.SetHideDOY()
-		g.SetHideMoon()
-		g.SetHideWeek()
-	}
+	g.applyPlain()
 
 	if g.OptSmall == true {
 		fontScale = 0.75
@@ -744,47 +1425,35 @@ func (g *Calendar) CreateCalendar(fn string) {
 
 	currentLanguage := getLanguage(g.OptLocale)
 
-	var fileEventList = make([]gDate, 10000) // Maximum number of events
-
-	if g.OptConfig != "" {
-		fileEventList = readConfigurationfile(g.OptConfig)
-	}
-
-	if len(g.OptICS) > 0 {
-		for _, evfile := range g.OptICS {
-			thiseventList := readICSfile(evfile, g.WantYear)
-			for _, ev := range thiseventList {
-				fileEventList = append(fileEventList, ev)
-			}
-		}
+	monthList, err := g.months()
+	if err != nil {
+		return err
 	}
+	rangeFrom, rangeTo := rangeBounds(monthList)
 
-	if len(g.OptConfigs) > 0 {
-		for _, evfile := range g.OptConfigs {
-			thiseventList := readConfigurationfile(evfile)
-			for _, ev := range thiseventList {
-				fileEventList = append(fileEventList, ev)
-			}
-		}
+	// The grid also shows days of the neighbor months.
+	eventList, holidayDays, err := g.events(rangeFrom.AddDate(0, 0, -7), rangeTo.AddDate(0, 0, 14))
+	if err != nil {
+		return err
 	}
-
-	eventList := fileEventList
-	for _, ev := range g.EventList {
-		eventList = append(eventList, ev)
+	store := newEventStore(eventList)
+	styles, order, err := g.styles()
+	if err != nil {
+		return err
 	}
+	days := dayStyles(store, styles, rangeFrom.AddDate(0, 0, -7), rangeTo.AddDate(0, 0, 14))
 
-	wantyear := g.WantYear
-	wantmonths := monthRange{g.WantBeginMonth, g.WantEndMonth}
 	localizedMonthNames := getLocalizedMonthNames(currentLanguage)
 	localizedWeekdayNames := getLocalizedWeekdayNames(currentLanguage, 0)
+	firstWeekday := g.firstWeekday(currentLanguage)
+	rtl := g.rtl(currentLanguage)
 
-	var calFont = g.OptFont
-
-	calFont, fontTempdir = processFont(calFont)
-
-	pdf := gofpdf.New(g.OptOrientation, "mm", g.OptPaperformat, fontTempdir)
+	pdf := gofpdf.New(g.OptOrientation, "mm", g.OptPaperformat, "")
 	pdf.SetTitle("Created with Gocal", true)
-	pdf.AddFont(calFont, "", calFont+".json")
+	calFont, err := g.setupFont(pdf)
+	if err != nil {
+		return err
+	}
 
 	PAGEWIDTH, PAGEHEIGHT, _ := pdf.PageSize(0)
 	if g.OptOrientation != "P" {
@@ -794,39 +1463,78 @@ func (g *Calendar) CreateCalendar(fn string) {
 	cw := (PAGEWIDTH - 2*MARGIN) / COLUMNS // cellwidth w margin
 	ch := PAGEHEIGHT / (LINES + 2)         // cellheight
 
-	var photoList [12]string
-	photoList = getPhotolist(g.OptPhoto, fontTempdir)
+	photoList, err := getPhotolist(g.OptPhoto, pdf)
+	if err != nil {
+		return err
+	}
 	if g.OptPhotos != "" {
-		photoList = getPhotoslist(g.OptPhotos)
+		photoList, err = getPhotoslist(g.OptPhotos)
+		if err != nil {
+			return err
+		}
 	}
 	if g.OptPhoto != "" || g.OptPhotos != "" {
 		ch *= 0.5
 	}
 
-	// Map of date to String for all days in the YEAR.
-	moonj := make(map[string]string)
-	computeMoonphasesJ(moonj, wantyear)
+	moons, err := g.moons(rangeFrom.AddDate(0, 0, -7), rangeTo.AddDate(0, 0, 14))
+	if err != nil {
+		return err
+	}
+
+	// cellText draws one line of text in the cell at x. It is
+	// left-aligned, or right-aligned in right-to-left mode.
+	cellText := func(s string, x, y float64) {
+		s = visual(s)
+		if rtl {
+			pdf.Text(x+0.98*cw-pdf.GetStringWidth(s), y, s)
+			return
+		}
+		pdf.Text(x+0.02*cw, y, s)
+	}
+
+	// The events that did not fit into the cells of the month.
+	var agenda []agendaItem
+
+	calendarTable := func(mymonth int, myyear int) error {
+		// In right-to-left mode the columns are mirrored.
+		left := pdf.GetX()
+		columnX := func(j int) float64 {
+			return left + float64(mirrorColumn(j, COLUMNS, rtl))*cw
+		}
 
-	calendarTable := func(mymonth int, myyear int) {
 		pdf.SetFont(calFont, "", WEEKDAYFONTSIZE*fontScale)
 		for weekday := 0; weekday <= 6; weekday++ { // Print weekdays in first row
+			// The names start with Saturday.
+			wd := (int(firstWeekday) + weekday + 1) % 7
 			// The week row can be smaller
-			pdf.CellFormat(cw, ch*0.33, localizedWeekdayNames[(weekday+2)%7], "0", 0, "C", false, 0, "")
+			pdf.SetX(columnX(weekday))
+			pdf.CellFormat(cw, ch*0.33, visual(localizedWeekdayNames[wd]), "0", 0, "C", false, 0, "")
 		}
 		pdf.Ln(-1)
 
 		// Figure out the first day in the calendar which depends on the weekday
-		// of the first day
-		var day int64 = 1
+		// of the first day. day counts from the first of the month.
 		t := time.Date(myyear, time.Month(mymonth), 1, 0, 0, 0, 0, time.UTC)
-
-		day -= int64(t.Weekday())
-		if day > 0 { // adjust silly exception where month starts w/ Sunday.
-			day -= 7
+		day := -int64((int(t.Weekday()) - int(firstWeekday) + 7) % 7)
+
+		// Multi-day events are drawn as bars across the day cells
+		// after the grid, in lanes above the other events.
+		gridStart := t.AddDate(0, 0, int(day))
+		spans, _ := spanLanes(store.multiDay, gridStart, gridStart.AddDate(0, 0, LINES*COLUMNS))
+		line := EVENTFONTSIZE * fontScale / 3.0
+		barY := 0.50 * ch
+		if g.OptSecondary != nil {
+			barY += DOYFONTSIZE * fontScale * 0.8 / 3.0
 		}
+		var rowY [LINES]float64
 
 		for i := 0; i < LINES; i++ {
+			rowY[i] = pdf.GetY()
+			rowStart := gridStart.AddDate(0, 0, COLUMNS*i)
+			rowLanes := lanesIn(spans, rowStart, rowStart.AddDate(0, 0, COLUMNS-1))
 			for j := 0; j < COLUMNS; j++ {
+				pdf.SetX(columnX(j))
 				pdf.SetFillColor(LIGHTGREY, LIGHTGREY, LIGHTGREY)
 				today := time.Date(myyear, time.Month(mymonth), 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(day) * 24 * 60 * 60 * time.Second)
 				fill := g.WantFill(i, j, today.Weekday())
@@ -836,7 +1544,7 @@ func (g *Calendar) CreateCalendar(fn string) {
 					pdf.SetTextColor(DARKGREY, DARKGREY, DARKGREY)
 					pdf.SetFillColor(LIGHTGREY, LIGHTGREY, LIGHTGREY)
 					fill = false // FIXME, do we want fill here?
-				} else if (today.Weekday() == time.Saturday || today.Weekday() == time.Sunday) && !g.OptNocolor {
+				} else if (today.Weekday() == time.Saturday || today.Weekday() == time.Sunday || holidayDays[today.Format("2006-01-02")]) && !g.OptNocolor {
 					pdf.SetTextColor(255, 0, 0) // RED
 				} else {
 					pdf.SetTextColor(BLACK, BLACK, BLACK)
@@ -849,28 +1557,53 @@ func (g *Calendar) CreateCalendar(fn string) {
 				}
 				pdf.SetCellMargin(CELLMARGIN)
 
+				// Background of the categories
+				if tint := tintOf(days[today.Format("2006-01-02")]); tint != nil && today.Month() == time.Month(mymonth) {
+					x, y := pdf.GetXY()
+					setFill(pdf, tint, LIGHTGREY)
+					pdf.Rect(x, y, cw, ch, "F")
+					pdf.SetFillColor(LIGHTGREY, LIGHTGREY, LIGHTGREY)
+					fill = false
+				}
+
 				if g.OptHideMoon == false {
-					// Do we have a relevant moon today?
-					todayString := today.Format("2006-01-02")
-					if m, ok := moonj[todayString]; ok == true {
-						x, y := pdf.GetXY()
-						moonLocX, moonLocY := x+cw*0.82, y+ch*0.2
+					x, y := pdf.GetXY()
+					moonLocX, moonLocY := x+cw*0.82, y+ch*0.2
+					if rtl {
+						moonLocX = x + cw*0.18
+					}
 
-						moonsize := MOONSIZE
-						if g.OptPhoto != "" || g.OptPhotos != "" {
-							moonsize *= 0.6
+					moonsize := MOONSIZE
+					if g.OptPhoto != "" || g.OptPhotos != "" {
+						moonsize *= 0.6
+					}
+					pdf.SetFillColor(LIGHTGREY, LIGHTGREY, LIGHTGREY)
+					m, ok := moons.phases[today.Format("2006-01-02")]
+					if moons.draw(pdf, today, moonLocX, moonLocY, moonsize) && ok {
+						// The name and the time of the phase, below the moon
+						var label []string
+						if g.OptMoonNames {
+							label = append(label, moonName(currentLanguage, m.name))
 						}
-						myMoonPDF := myPdf{pdf, moonsize}
-						pdf.SetFillColor(LIGHTGREY, LIGHTGREY, LIGHTGREY)
-						switch m {
-						case "Full":
-							myMoonPDF.fullMoon(moonLocX, moonLocY)
-						case "New":
-							myMoonPDF.newMoon(moonLocX, moonLocY)
-						case "First":
-							myMoonPDF.firstQuarter(moonLocX, moonLocY)
-						case "Last":
-							myMoonPDF.lastQuarter(moonLocX, moonLocY)
+						if g.OptMoonTimes {
+							label = append(label, m.t.Format("15:04"))
+						}
+						if len(label) > 0 {
+							s := visual(strings.Join(label, " "))
+							size := DOYFONTSIZE * fontScale * 0.6
+							pdf.SetFont(calFont, "", size)
+							if w := pdf.GetStringWidth(s); w > 0.6*cw {
+								size *= 0.6 * cw / w
+								pdf.SetFont(calFont, "", size)
+							}
+							tx := x + 0.98*cw - pdf.GetStringWidth(s)
+							if rtl {
+								tx = x + 0.02*cw
+							}
+							r, gr, b := pdf.GetTextColor()
+							pdf.SetTextColor(DARKGREY, DARKGREY, DARKGREY)
+							pdf.Text(tx, moonLocY+moonsize+size/3.0, s)
+							pdf.SetTextColor(r, gr, b)
 						}
 					}
 				}
@@ -879,80 +1612,197 @@ func (g *Calendar) CreateCalendar(fn string) {
 				if g.OptHideDOY == false && int(today.Month()) == mymonth {
 					doy := julian.DayOfYearGregorian(myyear, mymonth, int(today.Day()))
 					pdf.SetFont(calFont, "", DOYFONTSIZE*fontScale)
-					pdf.CellFormat(cw, ch, fmt.Sprintf("%d", doy), "1", 0, "BR", fill, 0, "")
+					pdf.CellFormat(cw, ch, fmt.Sprintf("%d", doy), "1", 0, mirrorAlign("BR", rtl), fill, 0, "")
 					pdf.SetX(pdf.GetX() - cw) // reset
 				}
 
 				// Add week number, lower left
-				if today.Weekday() == time.Monday && g.OptHideWeek == false {
+				if j == 0 && g.OptHideWeek == false {
 					pdf.SetFont(calFont, "", WEEKFONTSIZE*fontScale)
-					_, weeknr := today.ISOWeek()
-					pdf.CellFormat(cw, ch, fmt.Sprintf("W %d", weeknr), "1", 0, "BL", fill, 0, "")
+					pdf.CellFormat(cw, ch, fmt.Sprintf("W %d", weekNumber(today)), "1", 0, mirrorAlign("BL", rtl), fill, 0, "")
 					pdf.SetX(pdf.GetX() - cw) // reset
 				}
 
-				// Add event text
-				for _, ev := range eventList {
-					if len(ev.Text) == 0 {
-						continue
-					}
-					if today.Weekday().String() == string(ev.Weekday) {
-						x, y := pdf.GetXY()
-						pdf.SetFont(calFont, "", EVENTFONTSIZE*fontScale)
+				// Date in the secondary calendar, above the events
+				eventY := 0.50 * ch
+				if g.OptSecondary != nil {
+					x, y := pdf.GetXY()
+					pdf.SetFont(calFont, "", DOYFONTSIZE*fontScale*0.8)
+					cellText(g.OptSecondary.Format(today, false), x, y+eventY)
+					eventY += DOYFONTSIZE * fontScale * 0.8 / 3.0
+				}
+				eventY += float64(rowLanes) * line
 
+				// Add event text, the events of the day one below the
+				// other. Those that do not fit are counted in "+N more".
+				todays := store.on(today)
+				for i, ev := range todays {
+					todays[i].Text = g.eventText(ev, today, localizedWeekdayNames[(today.Weekday()+1)%7])
+				}
+				if len(todays) > 0 {
+					x, y := pdf.GetXY()
+					for _, ev := range todays {
 						if ev.Image != "" {
-							pdf.Image(ev.Image, x, y, cw, ch, false, "", 0, "")
-						}
-						for i, j := range strings.Split(ev.Text, "\\n") {
-							pdf.Text(x+0.02*cw, y+0.50*ch+float64(i)*EVENTFONTSIZE*fontScale/3.0, fmt.Sprintf("%s", j))
+							if err := addImage(pdf, ev.Image, x, y, cw, ch); err != nil {
+								return err
+							}
 						}
 					}
-					if today.Day() == ev.Day && today.Month() == ev.Month {
-						x, y := pdf.GetXY()
-						pdf.SetFont(calFont, "", EVENTFONTSIZE*fontScale)
-
-						if ev.Image != "" {
-							pdf.Image(ev.Image, x, y, cw, ch, false, "", 0, "")
+					pdf.SetFont(calFont, "", EVENTFONTSIZE*fontScale)
+					bottom := CELLMARGIN
+					if !g.OptHideDOY || !g.OptHideWeek {
+						bottom = DOYFONTSIZE * fontScale / 3.0
+					}
+					shown, hidden, size := fitEvents(pdf, todays, styles, 0.96*cw, ch-bottom-eventY, EVENTFONTSIZE*fontScale, g.OptShrink)
+					evLine := size / 3.0
+					r, gr, b := pdf.GetTextColor()
+					pdf.ClipRect(x, y, cw, ch, false)
+					n := 0
+					for _, ce := range shown {
+						// The category gives the color and a dot before the text.
+						st := styles[strings.ToLower(ce.ev.Category)]
+						if st.color != nil {
+							pdf.SetTextColor(st.color.r, st.color.g, st.color.b)
+						} else {
+							pdf.SetTextColor(r, gr, b)
+						}
+						tx := x
+						if st.dot != nil {
+							dotX := x + 0.02*cw + evLine/4
+							if rtl {
+								dotX = x + 0.98*cw - evLine/4
+							}
+							drawDots(pdf, []style{st}, dotX, y+eventY+float64(n)*evLine-evLine/3, evLine/4)
+							if rtl {
+								tx -= evLine / 2
+							} else {
+								tx += evLine / 2
+							}
 						}
-						for i, j := range strings.Split(ev.Text, "\\n") {
-							pdf.Text(x+0.02*cw, y+0.50*ch+float64(i)*EVENTFONTSIZE*fontScale/3.0, fmt.Sprintf("%s", j))
+						for _, l := range ce.lines {
+							cellText(l, tx, y+eventY+float64(n)*evLine)
+							n++
 						}
 					}
+					pdf.SetTextColor(r, gr, b)
+					if len(hidden) > 0 {
+						cellText(moreText(g.OptLocale, len(hidden)), x, y+eventY+float64(n)*evLine)
+						if today.Month() == time.Month(mymonth) {
+							for _, ev := range hidden {
+								agenda = append(agenda, agendaItem{today, ev.Text})
+							}
+						}
+					}
+					pdf.ClipEnd()
 				}
 
 				// day of the month, big number
 				pdf.SetFont(calFont, "", MONTHDAYFONTSIZE*fontScale)
-				pdf.CellFormat(cw, ch, fmt.Sprintf("%d", today.Day()), "1", 0, "TL", fill, 0, "")
+				pdf.CellFormat(cw, ch, fmt.Sprintf("%d", today.Day()), "1", 0, mirrorAlign("TL", rtl), fill, 0, "")
 				day++
 			}
 			pdf.Ln(-1)
 		}
+
+		// Each bar is cut at the end of the week row and continues
+		// in the next row.
+		pdf.SetFont(calFont, "", EVENTFONTSIZE*fontScale)
+		for _, s := range spans {
+			first, last := s.first, s.last
+			if g.OptHideOtherMonths {
+				if first.Before(t) {
+					first = t
+				}
+				if monthEnd := t.AddDate(0, 1, -1); last.After(monthEnd) {
+					last = monthEnd
+				}
+			}
+			for i := 0; i < LINES; i++ {
+				rowStart := gridStart.AddDate(0, 0, COLUMNS*i)
+				a, b := first, last
+				if a.Before(rowStart) {
+					a = rowStart
+				}
+				if rowEnd := rowStart.AddDate(0, 0, COLUMNS-1); b.After(rowEnd) {
+					b = rowEnd
+				}
+				if a.After(b) {
+					continue
+				}
+				xa := columnX(int(a.Sub(rowStart).Hours() / 24))
+				xb := columnX(int(b.Sub(rowStart).Hours() / 24))
+				if xb < xa {
+					xa, xb = xb, xa
+				}
+				w := xb - xa + cw - 2*CELLMARGIN
+				y := rowY[i] + barY + float64(s.lane)*line
+				st := styles[strings.ToLower(s.ev.Category)]
+				setFill(pdf, st.tint, PALEGREY)
+				pdf.Rect(xa+CELLMARGIN, y-0.8*line, w, line, "F")
+				if st.color != nil {
+					pdf.SetTextColor(st.color.r, st.color.g, st.color.b)
+				} else {
+					pdf.SetTextColor(BLACK, BLACK, BLACK)
+				}
+				text := visual(g.eventText(s.ev, s.first, localizedWeekdayNames[(s.first.Weekday()+1)%7]))
+				for text != "" && pdf.GetStringWidth(text) > w-CELLMARGIN {
+					r := []rune(text)
+					if rtl {
+						text = string(r[1:])
+					} else {
+						text = string(r[:len(r)-1])
+					}
+				}
+				if rtl {
+					pdf.Text(xa+w-pdf.GetStringWidth(text), y-0.05*line, text)
+				} else {
+					pdf.Text(xa+2*CELLMARGIN, y-0.05*line, text)
+				}
+			}
+		}
+		return nil
 	}
 
-	for mo := wantmonths.begin; mo <= wantmonths.end; mo++ {
-		//fmt.Printf("Printing page %d\n", page)
+	for _, ym := range monthList {
+		mo := ym.month
 		pdf.AddPage()
 		if g.OptWallpaper != "" {
-			g.AddWallpaper(pdf, fontTempdir, PAGEWIDTH, PAGEHEIGHT)
+			if err := g.AddWallpaper(pdf, PAGEWIDTH, PAGEHEIGHT); err != nil {
+				return err
+			}
 		}
 
 		if g.OptPhoto != "" || g.OptPhotos != "" {
-			photo := photoList[mo-1] // this list is zero-based.
+			// The photos are counted from January of the first year.
+			photo := photoList[((ym.year-monthList[0].year)*12+mo-1)%len(photoList)]
 			if photo != "" {
-				pdf.Image(photo, 0, PAGEHEIGHT*0.5, PAGEWIDTH, PAGEHEIGHT*0.5, false, "", 0, "")
+				if err := addImage(pdf, photo, 0, PAGEHEIGHT*0.5, PAGEWIDTH, PAGEHEIGHT*0.5); err != nil {
+					return err
+				}
 			}
 		}
 
 		pdf.SetTextColor(BLACK, BLACK, BLACK)
 		pdf.SetFont(calFont, "", HEADERFONTSIZE*fontScale)
-		pdf.CellFormat(PAGEWIDTH-MARGIN, MARGIN, localizedMonthNames[mo]+" "+fmt.Sprintf("%d", wantyear), "", 0, "C", false, 0, "")
+		pdf.CellFormat(PAGEWIDTH-MARGIN, MARGIN, visual(localizedMonthNames[mo]+" "+fmt.Sprintf("%d", ym.year)), "", 0, "C", false, 0, "")
 		pdf.Ln(-1)
-		calendarTable(mo, wantyear)
+		agenda = nil
+		if err := calendarTable(mo, ym.year); err != nil {
+			return err
+		}
 
 		pdf.Ln(-1)
+		if g.OptLegend {
+			legendX := MARGIN
+			if rtl {
+				legendX = PAGEWIDTH - MARGIN
+			}
+			drawLegend(pdf, styles, order, legendX, pdf.GetY()+FOOTERFONTSIZE*fontScale/3.0, FOOTERFONTSIZE*fontScale*0.8, rtl)
+		}
 		pdf.SetTextColor(DARKGREY, DARKGREY, DARKGREY)
 		pdf.SetFont(calFont, "", FOOTERFONTSIZE*fontScale)
-		pdf.Text(0.50*PAGEWIDTH-pdf.GetStringWidth(g.OptFooter)*0.5, 0.95*PAGEHEIGHT, fmt.Sprintf("%s", g.OptFooter))
+		footer := visual(g.OptFooter)
+		pdf.Text(0.50*PAGEWIDTH-pdf.GetStringWidth(footer)*0.5, 0.95*PAGEHEIGHT, footer)
 
 		pdf.TransformBegin() // TODO Hardcoded A4 portrait
 		ctrX := 210.0 * 0.96
@@ -960,7 +1810,18 @@ func (g *Calendar) CreateCalendar(fn string) {
 		pdf.TransformRotate(270, ctrX, ctrY)
 		pdf.Text(ctrX, ctrY, fmt.Sprintf("%s", g.OptMargin))
 		pdf.TransformEnd()
+
+		// The events that did not fit follow on a page of their own.
+		if g.OptAgenda && len(agenda) > 0 {
+			pdf.AddPage()
+			pdf.SetTextColor(BLACK, BLACK, BLACK)
+			pdf.SetFont(calFont, "", HEADERFONTSIZE*fontScale*0.75)
+			pdf.CellFormat(PAGEWIDTH-MARGIN, MARGIN, visual(localizedMonthNames[mo]+" "+fmt.Sprintf("%d", ym.year)), "", 0, "C", false, 0, "")
+			pdf.Ln(-1)
+			pdf.Ln(MARGIN / 2)
+			pdf.SetX(MARGIN)
+			drawAgenda(pdf, agenda, localizedWeekdayNames[:], PAGEWIDTH-2*MARGIN, EVENTFONTSIZE*fontScale*1.2, rtl)
+		}
 	}
-	pdf.OutputAndClose(docWriter(pdf, fn))
-	removeTempdir(fontTempdir)
+	return writePDF(pdf, w)
 }
@@ -0,0 +1,157 @@
+// Copyright (c) 2014 Stefan Schroeder, NY, 2014-03-10
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file
+
+package gocal
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_firstWeekday(t *testing.T) {
+	for _, c := range []struct {
+		locale string
+		set    int
+		want   time.Weekday
+	}{
+		{"de_DE", -1, time.Monday},
+		{"en_US", -1, time.Sunday},
+		{"fa_IR", -1, time.Saturday},
+		{"de_DE", int(time.Sunday), time.Sunday},
+		{"en_US", int(time.Wednesday), time.Wednesday},
+	} {
+		g := New(1, 1, 2022)
+		g.OptFirstWeekday = c.set
+		if got := g.firstWeekday(c.locale); got != c.want {
+			t.Errorf("%s, %d: got %v, want %v", c.locale, c.set, got, c.want)
+		}
+	}
+}
+
+func Test_weekNumber(t *testing.T) {
+	// The first row of January 2022 begins on the first weekday on
+	// or before the 1st, a Saturday. Its week is that of its Monday.
+	first := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, c := range []struct {
+		wd   time.Weekday
+		row  string
+		week int
+	}{
+		{time.Monday, "2021-12-27", 52},
+		{time.Sunday, "2021-12-26", 52},
+		{time.Saturday, "2022-01-01", 1},
+	} {
+		row := first.AddDate(0, 0, -((int(first.Weekday()) - int(c.wd) + 7) % 7))
+		if got := row.Format("2006-01-02"); got != c.row {
+			t.Errorf("%v: first row begins %s, want %s", c.wd, got, c.row)
+		}
+		if got := weekNumber(row); got != c.week {
+			t.Errorf("%v: week %d, want %d", c.wd, got, c.week)
+		}
+	}
+}
+
+func Test_getLanguage(t *testing.T) {
+	for in, want := range map[string]string{
+		"de_DE":       "de_DE",
+		"ru_RU.UTF-8": "ru_RU",
+		"de_DE@euro":  "de_DE",
+		"xx_YY":       "en_US",
+	} {
+		if got := getLanguage(in); got != want {
+			t.Errorf("%s: got %s, want %s", in, got, want)
+		}
+	}
+}
+
+func Test_localizedNames(t *testing.T) {
+	// The names are kept in UTF-8, and shortened by letters.
+	for _, c := range []struct {
+		locale, january, saturday, short string
+	}{
+		{"ru_RU", "Январь", "Суббота", "Су"},
+		{"el_GR", "Ιανουάριος", "Σάββατο", "Σά"},
+		{"pl_PL", "Styczeń", "Sobota", "So"},
+		{"bg_BG", "Януари", "Събота", "Съ"},
+	} {
+		if got := getLocalizedMonthNames(c.locale)[1]; got != c.january {
+			t.Errorf("%s: got %q, want %q", c.locale, got, c.january)
+		}
+		if got := getLocalizedWeekdayNames(c.locale, 0)[0]; got != c.saturday {
+			t.Errorf("%s: got %q, want %q", c.locale, got, c.saturday)
+		}
+		if got := getLocalizedWeekdayNames(c.locale, 2)[0]; got != c.short {
+			t.Errorf("%s: got %q, want %q", c.locale, got, c.short)
+		}
+	}
+}
+
+func Test_visual(t *testing.T) {
+	for in, want := range map[string]string{
+		"Hello, world":        "Hello, world",
+		"שלום":                "םולש",
+		"ינואר 15":            "15 ראוני",
+		"שנה 2024 טובה":       "הבוט 2024 הנש",
+		"חנוכה Meeting 10:00": "Meeting 10:00 הכונח",
+		"Meeting חנוכה today": "Meeting הכונח today",
+	} {
+		if got := visual(in); got != want {
+			t.Errorf("%q: got %q, want %q", in, got, want)
+		}
+	}
+}
+
+func Test_spanLanes(t *testing.T) {
+	day := func(m time.Month, d int) time.Time { return time.Date(2026, m, d, 0, 0, 0, 0, time.UTC) }
+	events := []Event{
+		{Month: time.August, Day: 5, Text: "Conference", Year: 2026, Days: 3},
+		{Month: time.July, Day: 27, Text: "Vacation", Year: 2026, Days: 19}, // to August 14
+		{Month: time.August, Day: 10, Text: "Trip", Year: 2026, Days: 3},
+		{Month: time.August, Day: 15, Text: "Fair", Year: 2026, Days: 2},
+		{Month: time.July, Day: 20, Text: "July", Year: 2026, Days: 12}, // to July 31
+		{Month: time.August, Day: 20, Text: "Single", Year: 2026, Days: 1},
+		{Month: time.August, Day: 20, Text: "Yearly", Days: 3},
+	}
+	spans, lanes := spanLanes(events, day(time.August, 1), day(time.September, 1))
+	if lanes != 2 {
+		t.Errorf("got %d lanes, want 2", lanes)
+	}
+	got := map[string]int{}
+	for _, s := range spans {
+		got[s.ev.Text] = s.lane
+	}
+	want := map[string]int{"Vacation": 0, "Conference": 1, "Trip": 1, "Fair": 0}
+	if len(got) != len(want) {
+		t.Errorf("got spans %v, want %v", got, want)
+	}
+	for text, lane := range want {
+		if l, ok := got[text]; !ok || l != lane {
+			t.Errorf("%s: got lane %d (%v), want %d", text, l, ok, lane)
+		}
+	}
+
+	// The span from July keeps its first day.
+	for _, c := range []struct {
+		t    time.Time
+		want string
+	}{
+		{day(time.July, 31), "Vacation"},
+		{day(time.August, 6), "Vacation Conference"},
+		{day(time.August, 15), "Fair"},
+		{day(time.August, 20), ""},
+	} {
+		var texts []string
+		for _, s := range spansOn(spans, c.t) {
+			texts = append(texts, s.ev.Text)
+		}
+		if got := strings.Join(texts, " "); got != c.want {
+			t.Errorf("%s: got %q, want %q", c.t.Format("2006-01-02"), got, c.want)
+		}
+	}
+	if n := lanesIn(spans, day(time.August, 15), day(time.August, 21)); n != 1 {
+		t.Errorf("got %d lanes in the week of August 15, want 1", n)
+	}
+}
@@ -6,10 +6,21 @@
 package gocal_test
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"github.com/StefanSchroeder/Gocal"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"runtime"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 var outdir = "test-output" + string(os.PathSeparator)
@@ -20,14 +31,18 @@ func Test_Example00(t *testing.T) {
 
 func Test_Example01(t *testing.T) {
 	g := gocal.New(1, 12, 2010)
-	g.CreateCalendar(outdir + "test-example01.pdf")
+	if err := g.CreateCalendar(outdir + "test-example01.pdf"); err != nil {
+		t.Error(err)
+	}
 }
 
 func Test_Example02(t *testing.T) {
 	g := gocal.New(1, 1, 2011)
 	g.SetNocolor()
 	g.SetOrientation("L")
-	g.CreateCalendar(outdir + "test-example02.pdf")
+	if err := g.CreateCalendar(outdir + "test-example02.pdf"); err != nil {
+		t.Error(err)
+	}
 }
 
 func Test_Example03(t *testing.T) {
@@ -35,14 +50,18 @@ func Test_Example03(t *testing.T) {
 	g.SetOrientation("P")
 	g.SetLocale("fr_FR")
 	g.SetFont("sans")
-	g.CreateCalendar(outdir + "test-example03.pdf")
+	if err := g.CreateCalendar(outdir + "test-example03.pdf"); err != nil {
+		t.Error(err)
+	}
 }
 
 func Test_Example04(t *testing.T) {
 	g := gocal.New(1, 1, 2015)
 	g.SetOrientation("P")
 	g.SetPhotos("gocalendar" + string(os.PathSeparator) + "pics")
-	g.CreateCalendar(outdir + "test-example04.pdf")
+	if err := g.CreateCalendar(outdir + "test-example04.pdf"); err != nil {
+		t.Error(err)
+	}
 }
 
 func Test_Example05(t *testing.T) {
@@ -50,7 +69,9 @@ func Test_Example05(t *testing.T) {
 	g.SetOrientation("L")
 	g.SetFont("mono")
 	g.SetLocale("de_DE")
-	g.CreateCalendar(outdir + "test-example05.pdf")
+	if err := g.CreateCalendar(outdir + "test-example05.pdf"); err != nil {
+		t.Error(err)
+	}
 }
 
 func Test_Example06(t *testing.T) {
@@ -58,7 +79,9 @@ func Test_Example06(t *testing.T) {
 	g.SetOrientation("P")
 	g.SetPlain()
 	g.SetLocale("nl_NL")
-	g.CreateCalendar(outdir + "test-example06.pdf")
+	if err := g.CreateCalendar(outdir + "test-example06.pdf"); err != nil {
+		t.Error(err)
+	}
 }
 
 func Test_Example07(t *testing.T) {
@@ -67,38 +90,50 @@ func Test_Example07(t *testing.T) {
 		g.SetFont("c:\\windows\\Fonts\\cabalett.ttf")
 	}
 	g.SetFooter("Windows specific Font inclusion example")
-	g.CreateCalendar(outdir + "test-example07.pdf")
+	if err := g.CreateCalendar(outdir + "test-example07.pdf"); err != nil {
+		t.Error(err)
+	}
 }
 
 func Test_Example08(t *testing.T) {
 	g := gocal.New(3, 4, 2013)
 	g.SetPhoto("gocalendar" + string(os.PathSeparator) + "pics" + string(os.PathSeparator) + "taxi.JPG")
 	g.SetOrientation("P")
-	g.CreateCalendar(outdir + "test-example08.pdf")
+	if err := g.CreateCalendar(outdir + "test-example08.pdf"); err != nil {
+		t.Error(err)
+	}
 }
 
 func Test_Example09(t *testing.T) {
 	g := gocal.New(3, 4, 2013)
 	g.SetLocale("fi_FI")
-	g.CreateCalendar(outdir + "test-example09.pdf")
+	if err := g.CreateCalendar(outdir + "test-example09.pdf"); err != nil {
+		t.Error(err)
+	}
 }
 
 func Test_Example10(t *testing.T) {
 	g := gocal.New(3, 4, 2013)
 	g.SetFontScale(0.5)
-	g.CreateCalendar(outdir + "test-example10.pdf")
+	if err := g.CreateCalendar(outdir + "test-example10.pdf"); err != nil {
+		t.Error(err)
+	}
 }
 
 func Test_Example11(t *testing.T) {
 	g := gocal.New(3, 4, 2013)
 	g.SetSmall()
-	g.CreateCalendar(outdir + "test-example11.pdf")
+	if err := g.CreateCalendar(outdir + "test-example11.pdf"); err != nil {
+		t.Error(err)
+	}
 }
 
 func Test_Example12(t *testing.T) {
 	g := gocal.New(3, 4, 2013)
 	g.SetHideOtherMonth()
-	g.CreateCalendar(outdir + "test-example12.pdf")
+	if err := g.CreateCalendar(outdir + "test-example12.pdf"); err != nil {
+		t.Error(err)
+	}
 }
 
 func Test_Example13(t *testing.T) {
@@ -109,58 +144,1152 @@ func Test_Example13(t *testing.T) {
 	g.AddEvent(17, 4, "Hedgehog\\nvisits", "")
 	g.AddEvent(18, 4, "Day\\nof the\\nDay", "")
 	g.SetHideMoon()
-	g.CreateCalendar(outdir + "test-example13.pdf")
+	if err := g.CreateCalendar(outdir + "test-example13.pdf"); err != nil {
+		t.Error(err)
+	}
 }
 
 func Test_Example14(t *testing.T) {
 	g := gocal.New(1, 12, 2013)
 	g.SetConfig("test-gocal.xml")
-	g.CreateCalendar(outdir + "test-example14.pdf")
+	if err := g.CreateCalendar(outdir + "test-example14.pdf"); err != nil {
+		t.Error(err)
+	}
 }
 
 func Test_Example15(t *testing.T) {
 	g := gocal.New(1, 12, 2019)
 	g.SetYearSpread(2)
 	g.SetFooter("Spread 2")
-	g.CreateYearCalendar(outdir + "test-example15.pdf")
+	if err := g.CreateYearCalendar(outdir + "test-example15.pdf"); err != nil {
+		t.Error(err)
+	}
 }
 
 func Test_Example16(t *testing.T) {
 	g := gocal.New(1, 12, 2019)
-	g.CreateYearCalendarInverse(outdir + "test-example16.pdf")
+	if err := g.CreateYearCalendarInverse(outdir + "test-example16.pdf"); err != nil {
+		t.Error(err)
+	}
 }
 
 func Test_Example17(t *testing.T) {
 	g := gocal.New(1, 12, 2019)
 	g.SetYearSpread(4)
 	g.SetFooter("Spread 4")
-	g.CreateYearCalendarInverse(outdir + "test-example17.pdf")
+	if err := g.CreateYearCalendarInverse(outdir + "test-example17.pdf"); err != nil {
+		t.Error(err)
+	}
 }
 
 func Test_Example18(t *testing.T) {
 	g := gocal.New(1, 12, 2019)
 	g.SetYearSpread(4)
 	g.SetFooter("Spread 4")
-	g.CreateYearCalendarInverse(outdir + "test-example18.pdf")
+	if err := g.CreateYearCalendarInverse(outdir + "test-example18.pdf"); err != nil {
+		t.Error(err)
+	}
 }
 
 func Test_Example19(t *testing.T) {
 	g := gocal.New(1, 12, 2019)
 	g.SetYearSpread(3)
 	g.SetFooter("Spread 3")
-	g.CreateYearCalendarInverse(outdir + "test-example19.pdf")
+	if err := g.CreateYearCalendarInverse(outdir + "test-example19.pdf"); err != nil {
+		t.Error(err)
+	}
 }
 
 func Test_Example20(t *testing.T) {
 	g := gocal.New(1, 12, 2020)
 	g.SetFooter("Fillmode")
 	g.WantFillMode("C")
-	g.CreateCalendar(outdir + "test-example20.pdf")
+	if err := g.CreateCalendar(outdir + "test-example20.pdf"); err != nil {
+		t.Error(err)
+	}
 }
 
 func Test_Example21(t *testing.T) {
 	g := gocal.New(1, 12, 2021)
 	g.SetFooter("Small")
 	g.SetSmall()
-	g.CreateCalendar(outdir + "test-example21.pdf")
+	if err := g.CreateCalendar(outdir + "test-example21.pdf"); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test_Errors(t *testing.T) {
+	g := gocal.New(1, 1, 2022)
+	g.SetFont("does-not-exist.ttf")
+	if err := g.CreateCalendar(outdir + "test-errors.pdf"); !errors.Is(err, gocal.ErrFont) {
+		t.Errorf("expected ErrFont, got %v", err)
+	}
+
+	g = gocal.New(1, 1, 2022)
+	g.AddConfig("does-not-exist.xml")
+	if err := g.CreateCalendar(outdir + "test-errors.pdf"); !errors.Is(err, gocal.ErrConfig) {
+		t.Errorf("expected ErrConfig, got %v", err)
+	}
+
+	g = gocal.New(1, 1, 2022)
+	g.AddConfig("gocal.go")
+	if err := g.CreateCalendar(outdir + "test-errors.pdf"); !errors.Is(err, gocal.ErrConfig) {
+		t.Errorf("expected ErrConfig, got %v", err)
+	}
+
+	g = gocal.New(1, 1, 2022)
+	g.AddICS("does-not-exist.ics")
+	if err := g.CreateCalendar(outdir + "test-errors.pdf"); !errors.Is(err, gocal.ErrICS) {
+		t.Errorf("expected ErrICS, got %v", err)
+	}
+
+	g = gocal.New(1, 1, 2022)
+	g.SetPhoto("does-not-exist.png")
+	if err := g.CreateCalendar(outdir + "test-errors.pdf"); !errors.Is(err, gocal.ErrPhoto) {
+		t.Errorf("expected ErrPhoto, got %v", err)
+	}
+
+	g = gocal.New(1, 1, 2022)
+	if err := g.CreateYearCalendar(outdir + "no-such-dir" + string(os.PathSeparator) + "x.pdf"); !errors.Is(err, gocal.ErrOutput) {
+		t.Errorf("expected ErrOutput, got %v", err)
+	}
+}
+
+func Test_Writer(t *testing.T) {
+	for i, create := range []func(*gocal.Calendar, *bytes.Buffer) error{
+		func(g *gocal.Calendar, b *bytes.Buffer) error { return g.CreateCalendarTo(b) },
+		func(g *gocal.Calendar, b *bytes.Buffer) error { return g.CreateYearCalendarTo(b) },
+		func(g *gocal.Calendar, b *bytes.Buffer) error { return g.CreateYearCalendarInverseTo(b) },
+	} {
+		var buf bytes.Buffer
+		g := gocal.New(1, 2, 2023)
+		if err := create(g, &buf); err != nil {
+			t.Errorf("%d: %v", i, err)
+		}
+		if !bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")) {
+			t.Errorf("%d: output is not a PDF", i)
+		}
+	}
+}
+
+func Test_Range(t *testing.T) {
+	g := gocal.NewRange(2026, 9, 2027, 8)
+	g.AddICS("gocalendar" + string(os.PathSeparator) + "data" + string(os.PathSeparator) + "german.ics")
+	if err := g.CreateCalendar(outdir + "test-range.pdf"); err != nil {
+		t.Error(err)
+	}
+	g.SetYearSpread(2)
+	if err := g.CreateYearCalendar(outdir + "test-range-a.pdf"); err != nil {
+		t.Error(err)
+	}
+	if err := g.CreateYearCalendarInverse(outdir + "test-range-b.pdf"); err != nil {
+		t.Error(err)
+	}
+
+	// A month on every page, across the end of the year.
+	g = gocal.NewRange(2025, 11, 2026, 2)
+	var b bytes.Buffer
+	if err := g.CreateCalendarTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(b.String(), "/Type /Page\n"); n != 4 {
+		t.Errorf("got %d pages from 2025-11 to 2026-02, want 4", n)
+	}
+
+	g = gocal.NewRange(2027, 8, 2026, 9)
+	if err := g.CreateCalendar(outdir + "test-range.pdf"); !errors.Is(err, gocal.ErrRange) {
+		t.Errorf("expected ErrRange, got %v", err)
+	}
+	for _, create := range []func(io.Writer) error{g.CreateYearCalendarTo, g.CreateYearCalendarInverseTo, g.CreateICSTo} {
+		if err := create(ioutil.Discard); !errors.Is(err, gocal.ErrRange) {
+			t.Errorf("expected ErrRange, got %v", err)
+		}
+	}
+}
+
+func Test_FirstWeekday(t *testing.T) {
+	g := gocal.New(1, 2, 2022)
+	g.SetFirstWeekday(time.Sunday)
+	g.SetFillpattern("X")
+	if err := g.CreateCalendar(outdir + "test-sunday.pdf"); err != nil {
+		t.Error(err)
+	}
+	g = gocal.New(1, 2, 2022)
+	g.SetFirstWeekday(time.Saturday)
+	if err := g.CreateYearCalendar(outdir + "test-saturday.pdf"); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test_Unicode(t *testing.T) {
+	for _, lang := range []string{"ru_RU", "el_GR", "pl_PL", "cs_CZ", "bg_BG"} {
+		g := gocal.New(1, 1, 2024)
+		g.SetLocale(lang)
+		g.AddEvent(7, 1, "Рождество Χριστούγεννα Żółć", "")
+		if err := g.CreateCalendar(outdir + "test-unicode-" + lang + ".pdf"); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func Test_RTL(t *testing.T) {
+	for _, lang := range []string{"he_IL", "ar_SA", "ar_EG", "fa_IR"} {
+		g := gocal.New(1, 12, 2024)
+		g.SetLocale(lang)
+		g.AddEvent(7, 1, "חנוכה Meeting 10:00", "")
+		g.AddEvent(8, 1, "اجتماع", "")
+		if err := g.CreateCalendar(outdir + "test-rtl-" + lang + ".pdf"); err != nil {
+			t.Error(err)
+		}
+		if err := g.CreateYearCalendar(outdir + "test-rtl-yearA-" + lang + ".pdf"); err != nil {
+			t.Error(err)
+		}
+		if err := g.CreateYearCalendarInverse(outdir + "test-rtl-yearB-" + lang + ".pdf"); err != nil {
+			t.Error(err)
+		}
+	}
+
+	g := gocal.New(1, 1, 2024)
+	g.SetDirection("rtl")
+	if err := g.CreateCalendar(outdir + "test-rtl-en_US.pdf"); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test_Secondary(t *testing.T) {
+	for _, name := range []string{"julian", "hebrew", "hijri", "chinese"} {
+		g := gocal.New(1, 12, 2023)
+		g.SetSecondary(gocal.SecondaryCalendar(name))
+		g.AddEvent(14, 2, "Valentine", "")
+		if err := g.CreateCalendar(outdir + "test-secondary-" + name + ".pdf"); err != nil {
+			t.Error(err)
+		}
+		if err := g.CreateYearCalendar(outdir + "test-secondary-yearA-" + name + ".pdf"); err != nil {
+			t.Error(err)
+		}
+		if err := g.CreateYearCalendarInverse(outdir + "test-secondary-yearB-" + name + ".pdf"); err != nil {
+			t.Error(err)
+		}
+	}
+
+	day := time.Date(2023, 3, 22, 0, 0, 0, 0, time.UTC)
+	for name, want := range map[string]string{
+		"julian":  "9 Mar",
+		"hebrew":  "29 Adar",
+		"hijri":   "29 Shaʿbān",
+		"chinese": "1 Rùn Èryuè",
+	} {
+		if got := gocal.SecondaryCalendar(name).Format(day, false); got != want {
+			t.Errorf("%s: got %q, want %q", name, got, want)
+		}
+	}
+}
+
+func Test_Holidays(t *testing.T) {
+	for _, region := range []string{"US", "GB-SCT", "DE-BY", "JP", "HK", "TR"} {
+		g := gocal.New(1, 12, 2024)
+		g.SetHolidays(region)
+		if err := g.CreateCalendar(outdir + "test-holidays-" + region + ".pdf"); err != nil {
+			t.Error(err)
+		}
+		if err := g.CreateYearCalendar(outdir + "test-holidays-yearA-" + region + ".pdf"); err != nil {
+			t.Error(err)
+		}
+	}
+
+	for _, c := range []struct {
+		region  string
+		year    int
+		summary string
+		want    string
+	}{
+		{"US", 2024, "Thanksgiving Day", "20241128"},
+		{"DE-BY", 2025, "Fronleichnam", "20250619"},
+		{"DE-BE", 2025, "Fronleichnam", ""},
+		{"GB", 2022, "Christmas Day", "20221225"},
+		{"GB", 2022, "Christmas Day (substitute day)", "20221227"},
+		{"GB", 2022, "Boxing Day", "20221226"},
+	} {
+		g := gocal.New(1, 12, c.year)
+		g.SetHolidays(c.region)
+		g.SetExportHolidays()
+		var b bytes.Buffer
+		if err := g.CreateICSTo(&b); err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Join(icsDates(b.String(), c.summary), " "); got != c.want {
+			t.Errorf("%s %d %s: got %q, want %q", c.region, c.year, c.summary, got, c.want)
+		}
+	}
+
+	g := gocal.New(1, 1, 2024)
+	g.SetHolidays("DE-XX")
+	if err := g.CreateCalendar(outdir + "test-holidays-bad.pdf"); !errors.Is(err, gocal.ErrHolidays) {
+		t.Errorf("got %v, want ErrHolidays", err)
+	}
+}
+
+func Test_DatedEvents(t *testing.T) {
+	g := gocal.NewRange(2026, 5, 2027, 2)
+	g.SetConfig("test-dated.xml")
+	g.AddDatedEvent(2026, 12, 24, "Only 2026", "")
+	g.AddEvent(24, 12, "Every year", "")
+	if err := g.CreateCalendar(outdir + "test-dated.pdf"); err != nil {
+		t.Error(err)
+	}
+
+	// Events with a year or a range of years are only in those years.
+	for _, c := range []struct {
+		year int
+		want map[string]int
+	}{
+		{2026, map[string]int{"Only 2026": 1, "Every year": 1, "Launch": 1, "Invoice": 12, "Early leave": 0}},
+		{2027, map[string]int{"Only 2026": 0, "Every year": 1, "Launch": 0, "Invoice": 0, "Early leave": 53}},
+	} {
+		g := gocal.New(1, 12, c.year)
+		g.SetConfig("test-dated.xml")
+		g.AddDatedEvent(2026, 12, 24, "Only 2026", "")
+		g.AddEvent(24, 12, "Every year", "")
+		var b bytes.Buffer
+		if err := g.CreateICSTo(&b); err != nil {
+			t.Fatal(err)
+		}
+		for summary, want := range c.want {
+			if n := len(icsDates(b.String(), summary)); n != want {
+				t.Errorf("%d: %d times %q, want %d", c.year, n, summary, want)
+			}
+		}
+	}
+}
+
+// icsDates returns the days of the events with the summary in the
+// ICS file out.
+func icsDates(out, summary string) (dates []string) {
+	for _, ev := range strings.Split(out, "BEGIN:VEVENT")[1:] {
+		if !strings.Contains(ev, "SUMMARY:"+summary+"\r\n") {
+			continue
+		}
+		i := strings.Index(ev, "DTSTART;VALUE=DATE:")
+		if i >= 0 {
+			dates = append(dates, ev[i+19:i+27])
+		}
+	}
+	return dates
+}
+
+func Test_Recurrence(t *testing.T) {
+	g := gocal.New(1, 12, 2026)
+	g.SetConfig("test-recurrence.xml")
+	if err := g.CreateCalendar(outdir + "test-recurrence.pdf"); err != nil {
+		t.Error(err)
+	}
+
+	for _, c := range []struct {
+		y1, m1, y2, m2 int
+		summary        string
+		want           string
+	}{
+		{2026, 3, 2026, 5, "Team lunch", "20260327 20260424 20260529"},
+		{2026, 3, 2026, 5, "Board", "20260310 20260414 20260512"},
+		{2026, 3, 2026, 5, "Review", "20260302 20260316 20260330 20260413 20260427 20260511 20260525"},
+		{2026, 12, 2027, 1, "Review", "20261207 20261221"}, // until 2026-12-31
+		{2026, 12, 2027, 1, "Team lunch", "20261225 20270129"},
+		{2026, 3, 2026, 5, "Ascension", "20260514"},
+		{2027, 4, 2027, 6, "Ascension", "20270506"},
+		{2026, 1, 2026, 2, "Ascension", ""},
+	} {
+		g := gocal.NewRange(c.y1, c.m1, c.y2, c.m2)
+		g.SetConfig("test-recurrence.xml")
+		var b bytes.Buffer
+		if err := g.CreateICSTo(&b); err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Join(icsDates(b.String(), c.summary), " "); got != c.want {
+			t.Errorf("%s in %d-%02d..%d-%02d: got %q, want %q", c.summary, c.y1, c.m1, c.y2, c.m2, got, c.want)
+		}
+	}
+}
+
+func Test_RecurrenceViews(t *testing.T) {
+	// A rule without a start has the same days in the month view,
+	// which also reads the days of the neighbor months, and in the
+	// year view.
+	g := gocal.New(1, 3, 2026)
+	src := g.XMLSource("test-recurrence.xml")
+	from, to := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	dates := func(a, b time.Time) map[string]string {
+		evs, err := src.Events(a, b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := map[string]string{}
+		for _, ev := range evs {
+			d := time.Date(ev.Year, ev.Month, ev.Day, 0, 0, 0, 0, time.UTC)
+			if ev.Year != 0 && !d.Before(from) && d.Before(to) {
+				out[ev.Text] += d.Format(" 2006-01-02")
+			}
+		}
+		return out
+	}
+	month := dates(from.AddDate(0, 0, -7), to.AddDate(0, 0, 14))
+	year := dates(from, to)
+	for text, want := range map[string]string{
+		"Monthly":   " 2026-01-01 2026-02-01 2026-03-01",
+		"Fortnight": " 2026-01-13 2026-01-27 2026-02-10 2026-02-24 2026-03-10 2026-03-24",
+	} {
+		if month[text] != want || year[text] != want {
+			t.Errorf("%s: month view%s, year view%s, want%s", text, month[text], year[text], want)
+		}
+	}
+}
+
+func Test_MultiDay(t *testing.T) {
+	g := gocal.New(7, 12, 2026)
+	g.SetConfig("test-multiday.xml")
+	g.AddICS("gocalendar" + string(os.PathSeparator) + "data" + string(os.PathSeparator) + "multiday.ics")
+	if err := g.CreateCalendar(outdir + "test-multiday.pdf"); err != nil {
+		t.Error(err)
+	}
+	if err := g.CreateYearCalendar(outdir + "test-multiday-yearA.pdf"); err != nil {
+		t.Error(err)
+	}
+	if err := g.CreateYearCalendarInverse(outdir + "test-multiday-yearB.pdf"); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test_ICS(t *testing.T) {
+	g := gocal.New(1, 3, 2026)
+	g.AddICS("gocalendar" + string(os.PathSeparator) + "data" + string(os.PathSeparator) + "recurring.ics")
+	g.SetTimezone("Europe/Berlin")
+	g.SetShowTimes()
+	if err := g.CreateCalendar(outdir + "test-ics.pdf"); err != nil {
+		t.Error(err)
+	}
+
+	// 23:30 in New York is the next morning in Berlin.
+	for _, c := range []struct {
+		tz   string
+		want string
+	}{
+		{"America/New_York", "2026-03-20 23:30"},
+		{"Europe/Berlin", "2026-03-21 04:30"},
+		{"UTC", "2026-03-21 03:30"},
+	} {
+		g := gocal.New(3, 3, 2026)
+		g.SetTimezone(c.tz)
+		evs, err := g.ICSSource("gocalendar"+string(os.PathSeparator)+"data"+string(os.PathSeparator)+"newyork.ics").Events(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(evs) != 1 {
+			t.Fatalf("%s: got %d events, want 1", c.tz, len(evs))
+		}
+		ev := evs[0]
+		if got := fmt.Sprintf("%d-%02d-%02d %s", ev.Year, ev.Month, ev.Day, ev.Start.Format("15:04")); got != c.want {
+			t.Errorf("%s: got %s, want %s", c.tz, got, c.want)
+		}
+	}
+
+	g.SetTimezone("Mars/Olympus")
+	err := g.CreateCalendar(outdir + "test-ics-bad.pdf")
+	if !errors.Is(err, gocal.ErrTimezone) {
+		t.Errorf("got %v, want ErrTimezone", err)
+	}
+	if errors.Unwrap(err) == nil {
+		t.Errorf("%v: the error of the time zone database is lost", err)
+	}
+}
+
+// icsBlock returns the VEVENT of the export out with the summary.
+func icsBlock(out, summary string) string {
+	i := strings.Index(out, "SUMMARY:"+summary+"\r\n")
+	if i < 0 {
+		return ""
+	}
+	begin := strings.LastIndex(out[:i], "BEGIN:VEVENT")
+	return out[begin : i+strings.Index(out[i:], "END:VEVENT")]
+}
+
+func Test_OutlookICS(t *testing.T) {
+	var logged bytes.Buffer
+	g := gocal.New(3, 3, 2026)
+	g.AddICS("gocalendar" + string(os.PathSeparator) + "data" + string(os.PathSeparator) + "outlook.ics")
+	g.SetTimezone("UTC")
+	g.SetLogger(log.New(&logged, "", 0))
+	var b bytes.Buffer
+	if err := g.CreateICSTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	// 00:30 in W. Europe Standard Time is 23:30 UTC of the day before.
+	if ev := icsBlock(b.String(), "Review"); !strings.Contains(ev, "DTSTART:20260310T233000Z\r\n") {
+		t.Errorf("Review not on 2026-03-10:\n%s", ev)
+	}
+	// An unknown zone is taken as the zone of the calendar.
+	if ev := icsBlock(b.String(), "Lunch"); !strings.Contains(ev, "DTSTART:20260312T120000Z\r\n") {
+		t.Errorf("Lunch not on 2026-03-12:\n%s", ev)
+	}
+	if !strings.Contains(logged.String(), `"Customized Time Zone"`) {
+		t.Errorf("no warning about the unknown zone: %q", logged.String())
+	}
+}
+
+func Test_Categories(t *testing.T) {
+	g := gocal.New(1, 12, 2026)
+	g.SetConfig("test-categories.xml")
+	g.AddICS("gocalendar" + string(os.PathSeparator) + "data" + string(os.PathSeparator) + "recurring.ics")
+	g.SetHolidays("US")
+	g.SetStyle("test-style.xml")
+	g.AddCategoryStyle(gocal.CategoryStyle{Name: "Work", Label: "Office", Tint: "#e0e8f8"})
+	g.SetLegend()
+	if err := g.CreateCalendar(outdir + "test-categories.pdf"); err != nil {
+		t.Error(err)
+	}
+	if err := g.CreateYearCalendar(outdir + "test-categories-yearA.pdf"); err != nil {
+		t.Error(err)
+	}
+	if err := g.CreateYearCalendarInverse(outdir + "test-categories-yearB.pdf"); err != nil {
+		t.Error(err)
+	}
+
+	g.AddCategoryStyle(gocal.CategoryStyle{Name: "bad", Color: "red"})
+	if err := g.CreateCalendarTo(ioutil.Discard); !errors.Is(err, gocal.ErrConfig) {
+		t.Errorf("got %v, want ErrConfig", err)
+	}
+}
+
+func Test_Crowded(t *testing.T) {
+	pages := func(g *gocal.Calendar) int {
+		var b bytes.Buffer
+		if err := g.CreateCalendarTo(&b); err != nil {
+			t.Fatal(err)
+		}
+		return strings.Count(b.String(), "/Type /Page\n")
+	}
+	crowded := func() *gocal.Calendar {
+		g := gocal.New(5, 5, 2026)
+		for i := 1; i <= 9; i++ {
+			g.AddEvent(12, 5, fmt.Sprintf("Event number %d with a text that is too long for one line", i), "")
+		}
+		return g
+	}
+
+	if n := pages(crowded()); n != 1 {
+		t.Errorf("got %d pages, want 1", n)
+	}
+	g := crowded()
+	g.SetShrink()
+	g.SetAgenda()
+	if n := pages(g); n != 2 {
+		t.Errorf("got %d pages with the agenda, want 2", n)
+	}
+	if err := g.CreateCalendar(outdir + "test-crowded.pdf"); err != nil {
+		t.Error(err)
+	}
+
+	// A single event that is cut is listed in the agenda.
+	g = gocal.New(5, 5, 2026)
+	g.AddEvent(12, 5, strings.Repeat("A text that does not fit into one day cell. ", 12), "")
+	g.SetAgenda()
+	if n := pages(g); n != 2 {
+		t.Errorf("got %d pages for a cut event, want 2", n)
+	}
+
+	g = crowded()
+	g.SetLocale("de_DE")
+	if err := g.CreateCalendar(outdir + "test-crowded-de.pdf"); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test_Filter(t *testing.T) {
+	// The agenda page shows that the crowded day is still there.
+	for _, c := range []struct {
+		filter func(g *gocal.Calendar)
+		pages  int
+	}{
+		{func(g *gocal.Calendar) {}, 2},
+		{func(g *gocal.Calendar) { g.SetHideEvents() }, 1},
+		{func(g *gocal.Calendar) { g.SetTags("family", "holiday") }, 1},
+		{func(g *gocal.Calendar) { g.ExcludeSource("test") }, 2},
+	} {
+		g := gocal.New(5, 5, 2026)
+		g.SetConfig("test-categories.xml")
+		g.SetAgenda()
+		for i := 1; i <= 9; i++ {
+			g.AddEvent(12, 5, fmt.Sprintf("Event number %d with a text that is too long for one line", i), "")
+		}
+		c.filter(g)
+		var b bytes.Buffer
+		if err := g.CreateCalendarTo(&b); err != nil {
+			t.Fatal(err)
+		}
+		if n := strings.Count(b.String(), "/Type /Page\n"); n != c.pages {
+			t.Errorf("got %d pages, want %d", n, c.pages)
+		}
+	}
+
+	g := gocal.New(1, 12, 2026)
+	g.SetConfig("test-categories.xml")
+	g.AddICS("gocalendar" + string(os.PathSeparator) + "data" + string(os.PathSeparator) + "recurring.ics")
+	g.SetHolidays("US")
+	g.SetTags("family", "holiday")
+	g.ExcludeSource("Team")
+	if err := g.CreateYearCalendar(outdir + "test-filter-yearA.pdf"); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test_Placeholders(t *testing.T) {
+	g := gocal.New(3, 9, 2026)
+	g.SetConfig("test-placeholders.xml")
+	g.AddICS("gocalendar" + string(os.PathSeparator) + "data" + string(os.PathSeparator) + "recurring.ics")
+	g.AddEvent(1, 4, "{age} stays without an origin", "")
+	if err := g.CreateCalendar(outdir + "test-placeholders.pdf"); err != nil {
+		t.Error(err)
+	}
+
+	g = gocal.New(3, 10, 2026)
+	g.SetConfig("test-placeholders.xml")
+	g.AddICS("gocalendar" + string(os.PathSeparator) + "data" + string(os.PathSeparator) + "recurring.ics")
+	g.AddEvent(1, 4, "{age} stays without an origin", "")
+	var b bytes.Buffer
+	if err := g.CreateICSTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	for summary, want := range map[string]string{
+		`Ella and Tom\n28 years married`: "20260920",
+		`Wednesday\, day 182\, 183 left`: "20260701",
+		"Anna turns 36":                  "20260312",
+		"{age} stays without an origin":  "20260401",
+		"Nora":                           "20261002", // before the origin
+	} {
+		if got := strings.Join(icsDates(b.String(), summary), " "); got != want {
+			t.Errorf("%q: got %q, want %q", summary, got, want)
+		}
+	}
+}
+
+func Test_Tables(t *testing.T) {
+	data := "gocalendar" + string(os.PathSeparator) + "data" + string(os.PathSeparator)
+	fields, err := gocal.ParseFieldMap("date=First day, text=Name, end=Last day, category=Type")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields.DateFormat = "02.01.2006"
+	g := gocal.New(3, 8, 2026)
+	g.AddCSV(data+"staff.csv", fields)
+	g.AddJSON(data+"events.json", gocal.FieldMap{})
+	if err := g.CreateCalendar(outdir + "test-tables.pdf"); err != nil {
+		t.Error(err)
+	}
+
+	// The CSV file starts with a byte order mark and is separated by
+	// semicolons, one of them quoted.
+	g = gocal.New(1, 12, 2026)
+	g.AddCSV(data+"staff.csv", fields)
+	g.AddJSON(data+"events.json", gocal.FieldMap{})
+	var b bytes.Buffer
+	if err := g.CreateICSTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct{ summary, want string }{
+		{"Anna Berg", "DTSTART;VALUE=DATE:20260312\r\nDTEND;VALUE=DATE:20260313\r\nSUMMARY:Anna Berg\r\nCATEGORIES:birthday\r\n"},
+		{"Team offsite", "DTSTART;VALUE=DATE:20260520\r\nDTEND;VALUE=DATE:20260523\r\nSUMMARY:Team offsite\r\nCATEGORIES:work\r\n"},
+		{"Jonas Weber", "DTSTART;VALUE=DATE:20260804\r\nDTEND;VALUE=DATE:20260822\r\n"},
+		{"Payroll close", "DTSTART;VALUE=DATE:20260928\r\n"},
+		{"Earth Day", "DTSTART;VALUE=DATE:20260422\r\nDTEND;VALUE=DATE:20260423\r\nSUMMARY:Earth Day\r\nCATEGORIES:school\r\n"},
+		{"Halloween", "DTSTART;VALUE=DATE:20261031\r\n"},
+		{"Summer camp", "DTSTART;VALUE=DATE:20260629\r\nDTEND;VALUE=DATE:20260704\r\n"},
+	} {
+		if ev := icsBlock(b.String(), c.summary); !strings.Contains(ev, c.want) {
+			t.Errorf("%s: no %q in:\n%s", c.summary, c.want, ev)
+		}
+	}
+	if n := len(icsDates(b.String(), "Piano")); n != 52 {
+		t.Errorf("got %d times Piano, want every Friday", n)
+	}
+
+	if _, err := gocal.ParseFieldMap("when=Start"); err == nil {
+		t.Error("unknown field accepted")
+	}
+	dates := gocal.FieldMap{Date: "First day", Text: "Name", DateFormat: "2006-01-02"}
+	for _, add := range []func(g *gocal.Calendar){
+		func(g *gocal.Calendar) { g.AddCSV(data+"staff.csv", gocal.FieldMap{}) }, // no column date
+		func(g *gocal.Calendar) { g.AddCSV(data+"staff.csv", dates) },
+		func(g *gocal.Calendar) { g.AddJSON(data+"staff.csv", gocal.FieldMap{}) },
+	} {
+		g := gocal.New(3, 3, 2026)
+		add(g)
+		if err := g.CreateCalendarTo(ioutil.Discard); !errors.Is(err, gocal.ErrTable) {
+			t.Errorf("got %v, want ErrTable", err)
+		}
+	}
+}
+
+func Test_VCard(t *testing.T) {
+	g := gocal.New(1, 12, 2026)
+	g.AddVCard("gocalendar" + string(os.PathSeparator) + "data" + string(os.PathSeparator) + "contacts.vcf")
+	g.SetStyle("test-style.xml")
+	if err := g.CreateCalendar(outdir + "test-vcard.pdf"); err != nil {
+		t.Error(err)
+	}
+
+	// Apple writes the year 1604 for birthdays without a year, and
+	// there is no age before the year of birth.
+	for _, c := range []struct {
+		year int
+		want []string
+	}{
+		{2026, []string{"Mia Keller", "Paul Roth", "Lea Sommer"}},
+		{2028, []string{"Mia Keller", "Paul Roth", "Lea Sommer (1)"}},
+	} {
+		g := gocal.New(5, 5, c.year)
+		g.AddVCard("gocalendar" + string(os.PathSeparator) + "data" + string(os.PathSeparator) + "apple.vcf")
+		var b bytes.Buffer
+		if err := g.CreateICSTo(&b); err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range c.want {
+			if !strings.Contains(b.String(), "SUMMARY:"+want+"\r\n") {
+				t.Errorf("%d: no %q in:\n%s", c.year, want, b.String())
+			}
+		}
+	}
+
+	g = gocal.New(1, 1, 2026)
+	g.AddVCard("test-gocal.xml")
+	if err := g.CreateCalendarTo(ioutil.Discard); !errors.Is(err, gocal.ErrVCard) {
+		t.Errorf("got %v, want ErrVCard", err)
+	}
+}
+
+func Test_Export(t *testing.T) {
+	g := gocal.New(1, 3, 2026)
+	g.SetConfig("test-gocal.xml")
+	g.AddICS("gocalendar" + string(os.PathSeparator) + "data" + string(os.PathSeparator) + "recurring.ics")
+	g.SetHolidays("US")
+	g.SetExportMoon()
+	var b bytes.Buffer
+	if err := g.CreateICSTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"BEGIN:VCALENDAR\r\n", "SUMMARY:Anna turns 36\r\n", "DTSTART;VALUE=DATE:20260312\r\n", "SUMMARY:Full moon\r\n"} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("no %q in the export", want)
+		}
+	}
+	if strings.Contains(b.String(), "CATEGORIES:holiday") {
+		t.Error("holidays exported without SetExportHolidays")
+	}
+
+	// The export reads back.
+	r := gocal.New(1, 3, 2026)
+	r.AddICSReader(&b)
+	if err := r.CreateCalendar(outdir + "test-export.pdf"); err != nil {
+		t.Error(err)
+	}
+
+	g.SetExportHolidays()
+	if err := g.CreateICS(outdir + "test-export.ics"); err != nil {
+		t.Error(err)
+	}
+	data, err := ioutil.ReadFile(outdir + "test-export.ics")
+	if err != nil || !strings.Contains(string(data), "CATEGORIES:holiday") {
+		t.Errorf("no holidays in the export: %v", err)
+	}
+}
+
+func Test_ExportTimes(t *testing.T) {
+	export := func(g *gocal.Calendar) string {
+		var b bytes.Buffer
+		if err := g.CreateICSTo(&b); err != nil {
+			t.Fatal(err)
+		}
+		return b.String()
+	}
+	g := gocal.New(3, 3, 2026)
+	g.AddICS("gocalendar" + string(os.PathSeparator) + "data" + string(os.PathSeparator) + "recurring.ics")
+	g.SetTimezone("Europe/Berlin")
+	g.SetShowTimes()
+	out := export(g)
+
+	// Timed events keep their times, and the text has no time.
+	for _, c := range []struct{ summary, start, end string }{
+		{"Standup (moved)", "20260304T130000Z", "20260304T131500Z"},
+		{"Late call", "20260310T233000Z", "20260311T000000Z"},
+	} {
+		ev := icsBlock(out, c.summary)
+		if !strings.Contains(ev, "DTSTART:"+c.start+"\r\n") || !strings.Contains(ev, "DTEND:"+c.end+"\r\n") {
+			t.Errorf("%s not from %s to %s:\n%s", c.summary, c.start, c.end, ev)
+		}
+	}
+	if strings.Contains(out, "SUMMARY:09:30") {
+		t.Error("time in the summary")
+	}
+	if !strings.Contains(icsBlock(out, "Anna turns 36"), "DTSTART;VALUE=DATE:20260312\r\n") {
+		t.Error("all-day event not exported as a date")
+	}
+
+	// The export reads back to the same events.
+	r := gocal.New(3, 3, 2026)
+	r.AddICSReader(strings.NewReader(out))
+	back := export(r)
+	for _, line := range strings.Split(out, "\r\n") {
+		if strings.HasPrefix(line, "DTSTART") || strings.HasPrefix(line, "DTEND") || strings.HasPrefix(line, "SUMMARY") {
+			if !strings.Contains(back, line+"\r\n") {
+				t.Errorf("%q lost in the round trip", line)
+			}
+		}
+	}
+}
+
+func Test_Moon(t *testing.T) {
+	// The full moon of 2026-02-01 22:09 UTC is on the next day in Tokyo.
+	// Without a time zone it is in UTC, whatever the local time zone.
+	local := time.Local
+	time.Local = time.FixedZone("JST", 9*3600)
+	defer func() { time.Local = local }()
+	for _, c := range []struct {
+		loc  *time.Location
+		want string
+	}{
+		{nil, "20260201"},
+		{time.UTC, "20260201"},
+		{time.FixedZone("JST", 9*3600), "20260202"},
+	} {
+		g := gocal.New(1, 3, 2026)
+		if c.loc != nil {
+			g.SetMoonLocation(c.loc)
+		}
+		g.SetExportMoon()
+		var b bytes.Buffer
+		if err := g.CreateICSTo(&b); err != nil {
+			t.Fatal(err)
+		}
+		want := "DTSTART;VALUE=DATE:" + c.want + "\r\n"
+		if i := strings.Index(b.String(), want); i < 0 || !strings.Contains(b.String()[i:i+120], "SUMMARY:Full moon") {
+			t.Errorf("%v: no full moon on %s", c.loc, c.want)
+		}
+		if n := strings.Count(b.String(), "SUMMARY:"); n != 12 {
+			t.Errorf("%v: %d moon phases in three months, want 12", c.loc, n)
+		}
+	}
+
+	// The names are those of the language of the calendar.
+	g := gocal.New(2, 2, 2026)
+	g.SetLocale("de_DE")
+	g.SetExportMoon()
+	var b bytes.Buffer
+	if err := g.CreateICSTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	if got := icsDates(b.String(), "Vollmond"); len(got) != 1 || got[0] != "20260201" {
+		t.Errorf("got full moons %v, want [20260201]", got)
+	}
+
+	for _, mode := range []string{"", "quarters", "daily"} {
+		name := mode
+		if name == "" {
+			name = "symbols"
+		}
+		g := gocal.New(1, 12, 2026)
+		g.SetTimezone("Asia/Tokyo")
+		g.SetMoonMode(mode)
+		g.SetMoonTimes()
+		g.SetMoonNames()
+		g.SetYearMoons()
+		g.SetLocale("de_DE")
+		if mode == "daily" {
+			g.SetSouthern()
+		}
+		if err := g.CreateCalendar(outdir + "test-moon-" + name + ".pdf"); err != nil {
+			t.Error(err)
+		}
+		if err := g.CreateYearCalendar(outdir + "test-moon-yearA-" + name + ".pdf"); err != nil {
+			t.Error(err)
+		}
+		if err := g.CreateYearCalendarInverse(outdir + "test-moon-yearB-" + name + ".pdf"); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func Test_YearMoons(t *testing.T) {
+	size := func(set func(g *gocal.Calendar)) int {
+		g := gocal.New(1, 12, 2026)
+		set(g)
+		var b bytes.Buffer
+		if err := g.CreateYearCalendarTo(&b); err != nil {
+			t.Fatal(err)
+		}
+		return b.Len()
+	}
+	// The year calendar has no moons unless asked for.
+	none := size(func(g *gocal.Calendar) { g.SetHideMoon() })
+	if n := size(func(g *gocal.Calendar) {}); n != none {
+		t.Errorf("moons in the year calendar by default")
+	}
+	if n := size(func(g *gocal.Calendar) { g.SetYearMoons() }); n == none {
+		t.Errorf("no moons in the year calendar with SetYearMoons")
+	}
+	plain := size(func(g *gocal.Calendar) { g.SetPlain() })
+	if n := size(func(g *gocal.Calendar) { g.SetPlain(); g.SetYearMoons() }); n != plain {
+		t.Errorf("moons in the plain year calendar")
+	}
+}
+
+// dbSource is an EventSource like one backed by a database.
+type dbSource struct {
+	from, to time.Time
+	err      error
+}
+
+func (s *dbSource) Events(from, to time.Time) ([]gocal.Event, error) {
+	s.from, s.to = from, to
+	return []gocal.Event{{Month: time.March, Day: 20, Text: "Release", Year: 2026, Category: "work"}}, s.err
+}
+
+func Test_EventSource(t *testing.T) {
+	g := gocal.New(3, 4, 2026)
+	src := &dbSource{}
+	g.AddSource(src)
+	g.AddEvents(gocal.Event{Month: time.April, Day: 6, Text: "Trip", Year: 2026, Days: 3},
+		gocal.Event{Text: "Gym", Weekday: "Tuesday"})
+	var b bytes.Buffer
+	if err := g.CreateICSTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	if !src.from.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) || !src.to.Equal(time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("source asked for %v to %v", src.from, src.to)
+	}
+	for _, want := range []string{"SUMMARY:Release\r\nCATEGORIES:work\r\n", "DTEND;VALUE=DATE:20260409\r\nSUMMARY:Trip\r\n"} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("no %q in the export", want)
+		}
+	}
+	if n := strings.Count(b.String(), "SUMMARY:Gym\r\n"); n != 9 {
+		t.Errorf("%d events on Tuesdays, want 9", n)
+	}
+	if err := g.CreateCalendar(outdir + "test-source.pdf"); err != nil {
+		t.Error(err)
+	}
+
+	src.err = errors.New("database down")
+	if err := g.CreateCalendar(outdir + "test-source.pdf"); err != src.err {
+		t.Errorf("got %v, want the error of the source", err)
+	}
+
+	// Events over several days without a year repeat every year.
+	y := gocal.NewRange(2026, 12, 2027, 1)
+	y.AddEvents(gocal.Event{Month: time.December, Day: 30, Text: "Break", Days: 4})
+	b.Reset()
+	if err := y.CreateICSTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(b.String(), "SUMMARY:Break\r\n"); n != 1 || !strings.Contains(b.String(), "DTSTART;VALUE=DATE:20261230\r\nDTEND;VALUE=DATE:20270103\r\n") {
+		t.Errorf("%d yearly breaks, want one from 2026-12-30 to 2027-01-02", n)
+	}
+	if err := y.CreateCalendar(outdir + "test-source-yearly.pdf"); err != nil {
+		t.Error(err)
+	}
+
+	x := gocal.New(3, 3, 2026)
+	x.AddSource(x.XMLSource("test-gocal.xml"))
+	if err := x.CreateCalendar(outdir + "test-source-xml.pdf"); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test_ICSReader(t *testing.T) {
+	f, err := os.Open("gocalendar" + string(os.PathSeparator) + "data" + string(os.PathSeparator) + "recurring.ics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := gocal.New(3, 3, 2026)
+	g.AddICSReader(f)
+	f.Close()
+	if err := g.CreateCalendar(outdir + "test-ics-reader.pdf"); err != nil {
+		t.Error(err)
+	}
+
+	for _, bad := range []string{
+		"<html>Not found</html>",
+		"BEGIN:VCALENDAR\nBEGIN:VEVENT\nSUMMARY:No start\nEND:VEVENT\nEND:VCALENDAR\n",
+		"BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART:2026-03-01\nEND:VEVENT\nEND:VCALENDAR\n",
+		"BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART:20260301\n",
+	} {
+		g := gocal.New(3, 3, 2026)
+		g.AddICSReader(strings.NewReader(bad))
+		if err := g.CreateCalendarTo(ioutil.Discard); !errors.Is(err, gocal.ErrICS) {
+			t.Errorf("%q: got %v, want ErrICS", bad, err)
+		}
+	}
+
+	// Outlook starts its files with a byte order mark.
+	g = gocal.New(3, 3, 2026)
+	g.AddICSReader(strings.NewReader("\uFEFFBEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nDTSTART;VALUE=DATE:20260305\r\nSUMMARY:Marked\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"))
+	var b bytes.Buffer
+	if err := g.CreateICSTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	if got := icsDates(b.String(), "Marked"); len(got) != 1 || got[0] != "20260305" {
+		t.Errorf("got %v, want [20260305]", got)
+	}
+}
+
+func Test_Concurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			g := gocal.New(1, 12, 2026)
+			g.AddICS("gocalendar" + string(os.PathSeparator) + "data" + string(os.PathSeparator) + "recurring.ics")
+			if err := g.CreateCalendarTo(ioutil.Discard); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func Test_RemoteICS(t *testing.T) {
+	data, err := ioutil.ReadFile("gocalendar" + string(os.PathSeparator) + "data" + string(os.PathSeparator) + "recurring.ics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var downloads, revalidations int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow.ics" {
+			time.Sleep(500 * time.Millisecond)
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			revalidations++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads++
+		w.Header().Set("ETag", `"v1"`)
+		w.Write(data)
+	}))
+	cache := t.TempDir()
+
+	render := func(url string, offline bool) error {
+		g := gocal.New(3, 3, 2026)
+		g.AddICS(url)
+		g.SetCacheDir(cache)
+		g.SetTimeout(100 * time.Millisecond)
+		if offline {
+			g.SetOffline()
+		}
+		return g.CreateCalendarTo(ioutil.Discard)
+	}
+	if err := render(srv.URL+"/team.ics", false); err != nil {
+		t.Error(err)
+	}
+	if err := render(srv.URL+"/team.ics", false); err != nil {
+		t.Error(err)
+	}
+	if downloads != 1 || revalidations != 1 {
+		t.Errorf("got %d downloads and %d revalidations, want 1 and 1", downloads, revalidations)
+	}
+	if err := render(srv.URL+"/slow.ics", false); !errors.Is(err, gocal.ErrICS) {
+		t.Errorf("slow feed: got %v, want ErrICS", err)
+	}
+
+	srv.Close()
+	if err := render(srv.URL+"/team.ics", true); err != nil {
+		t.Errorf("offline: %v", err)
+	}
+	if err := render(srv.URL+"/team.ics", false); err != nil {
+		t.Errorf("server down: %v", err)
+	}
+	if err := render(srv.URL+"/other.ics", true); !errors.Is(err, gocal.ErrICS) {
+		t.Errorf("offline without copy: got %v, want ErrICS", err)
+	}
+
+	// A login page instead of the feed keeps the cached copy, a feed
+	// with a byte order mark replaces it.
+	login, bom := false, false
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if bom {
+			w.Write([]byte("\uFEFFBEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nDTSTART;VALUE=DATE:20260305\r\nSUMMARY:Fresh\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"))
+			return
+		}
+		if login {
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html><body>Please log in</body></html>"))
+			return
+		}
+		w.Write(data)
+	}))
+	defer srv.Close()
+	export := func() (string, error) {
+		g := gocal.New(3, 3, 2026)
+		g.AddICS(srv.URL + "/team.ics")
+		g.SetCacheDir(cache)
+		var b bytes.Buffer
+		err := g.CreateICSTo(&b)
+		return b.String(), err
+	}
+	if _, err := export(); err != nil {
+		t.Fatal(err)
+	}
+	login = true
+	out, err := export()
+	if err != nil {
+		t.Errorf("login page: %v", err)
+	}
+	if !strings.Contains(out, "SUMMARY:Anna turns") {
+		t.Error("login page: the cached events are missing")
+	}
+	bom = true
+	if out, err = export(); err != nil || !strings.Contains(out, "SUMMARY:Fresh") {
+		t.Errorf("feed with a byte order mark not used: %v", err)
+	}
+}
+
+// manyEvents returns n events of 2026 like those of a large company
+// calendar: most on a date, every tenth every year, every hundredth
+// every week and a few over several days.
+func manyEvents(n int) []gocal.Event {
+	weekdays := []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday"}
+	events := make([]gocal.Event, n)
+	for i := range events {
+		ev := gocal.Event{Month: time.Month(i%12 + 1), Day: i%28 + 1, Text: fmt.Sprintf("Event %d", i), Year: 2026}
+		switch {
+		case i%100 == 0:
+			ev.Weekday = weekdays[i/100%5]
+		case i%10 == 0:
+			ev.Year = 0
+		case i%1000 == 1:
+			ev.Days = 3
+		}
+		events[i] = ev
+	}
+	return events
+}
+
+func benchmarkCalendar(b *testing.B, create func(*gocal.Calendar) error) {
+	g := gocal.New(1, 12, 2026)
+	g.AddEvents(manyEvents(50000)...)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := create(g); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCalendar(b *testing.B) {
+	benchmarkCalendar(b, func(g *gocal.Calendar) error { return g.CreateCalendarTo(ioutil.Discard) })
+}
+
+func BenchmarkYearCalendar(b *testing.B) {
+	benchmarkCalendar(b, func(g *gocal.Calendar) error { return g.CreateYearCalendarTo(ioutil.Discard) })
+}
+
+func BenchmarkICS(b *testing.B) {
+	benchmarkCalendar(b, func(g *gocal.Calendar) error { return g.CreateICSTo(ioutil.Discard) })
 }
@@ -9,8 +9,10 @@ import (
 	"flag"
 	"fmt"
 	"github.com/StefanSchroeder/Gocal"
+	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -31,15 +33,54 @@ var configFiles arrayFlags
 // A list of options on the cmdline for ICS events
 var icsFiles arrayFlags
 
+// Lists of options on the cmdline for CSV and JSON events
+var csvFiles arrayFlags
+var jsonFiles arrayFlags
+
+// A list of options on the cmdline for vCard address books
+var vcardFiles arrayFlags
+
+// A list of options on the cmdline for the sources to leave out
+var excludeSources arrayFlags
+
 const VERSION = "0.9 the Unready"
 
+// parseWeekday parses an English weekday name, which may be
+// abbreviated to two letters.
+func parseWeekday(s string) (time.Weekday, error) {
+	s = strings.ToLower(s)
+	for wd := time.Sunday; wd <= time.Saturday; wd++ {
+		if len(s) >= 2 && strings.HasPrefix(strings.ToLower(wd.String()), s) {
+			return wd, nil
+		}
+	}
+	return time.Sunday, fmt.Errorf("unknown weekday '%s'", s)
+}
+
+// parseRange parses a range of months like 2026-09..2027-08.
+func parseRange(s string) (by, bm, ey, em int, err error) {
+	parts := strings.Split(s, "..")
+	if len(parts) != 2 {
+		return 0, 0, 0, 0, fmt.Errorf("range '%s' is not of the form YYYY-MM..YYYY-MM", s)
+	}
+	b, err := time.Parse("2006-01", parts[0])
+	if err != nil {
+		return 0, 0, 0, 0, fmt.Errorf("bad begin of range '%s'", s)
+	}
+	e, err := time.Parse("2006-01", parts[1])
+	if err != nil {
+		return 0, 0, 0, 0, fmt.Errorf("bad end of range '%s'", s)
+	}
+	return b.Year(), int(b.Month()), e.Year(), int(e.Month()), nil
+}
+
 var optFont = flag.String("font", "serif", "Font")
 var optFontScale = flag.Float64("fontscale", 1.0, "Font")
 var optYearSpread = flag.Int("spread", 1, "Spread year over multiple pages")
 var optFooter = flag.String("footer", "Gocal", "Footer note")
 var optHideDOY = flag.Bool("nodoy", false, "Hide day of year (false)")
 var optPlain = flag.Bool("plain", false, "Hide everything")
-var optHideEvents = flag.Bool("noevents", false, "Hide events from config file (false)")
+var optHideEvents = flag.Bool("noevents", false, "Hide events from config and ICS files (false)")
 var optHideMoon = flag.Bool("nomoon", false, "Hide moon phases (false)")
 var optHideWeek = flag.Bool("noweek", false, "Hide week number (false)")
 var optLocale = flag.String("lang", "", "Language")
@@ -48,7 +89,7 @@ var optPaper = flag.String("paper", "A4", "Paper format (A3 A4 A5 Letter Legal)"
 var optPhoto = flag.String("photo", "", "Show photo (single image PNG JPG GIF)")
 var optPhotos = flag.String("photos", "", "Show photos (directory PNG JPG GIF)")
 var optWallpaper = flag.String("wall", "", "Show wallpaper PNG JPG GIF")
-var outfilename = flag.String("o", "output.pdf", "Output filename")
+var outfilename = flag.String("o", "output.pdf", "Output filename (- for stdout)")
 var optSmall = flag.Bool("small", false, "Smaller fonts")
 var optHideOtherMonths = flag.Bool("noother", false, "Hide neighboring month days")
 var optNocolor = flag.Bool("nocolor", false, "Sundays and Saturdays in black, instead of red.")
@@ -57,10 +98,38 @@ var optYearB = flag.Bool("yearB", false, "Year calendar (design B)")
 var optFillpattern = flag.String("fill", "", "Set grid fill pattern.")
 var optVersion = flag.Bool("v", false, "Version.")
 var optMargin = flag.String("margin", "", "Margin comment")
+var optFirstday = flag.String("firstday", "", "First day of the week, e.g. Mon, Sun, Sat (from language)")
+var optDirection = flag.String("dir", "", "Layout direction rtl or ltr (from language)")
+var optSecondary = flag.String("second", "", "Secondary calendar julian, hebrew, hijri or chinese")
+var optTimezone = flag.String("tz", "", "Time zone of the ICS events and moon phases, e.g. Europe/Berlin (local, UTC for the moon)")
+var optCache = flag.String("cache", "", "Directory for the cached ICS feeds, e.g. ~/.cache/gocal (none)")
+var optOffline = flag.Bool("offline", false, "Use the cached ICS feeds only (false)")
+var optTimeout = flag.Duration("timeout", 30*time.Second, "Time limit for downloading an ICS feed")
+var optTimes = flag.Bool("times", false, "Print the start time of ICS events (false)")
+var optMoonTimes = flag.Bool("moontimes", false, "Print the time of the moon phases (false)")
+var optMoonNames = flag.Bool("moonnames", false, "Print the name of the moon phases in the language of the calendar (false)")
+var optMoonMode = flag.String("moonmode", "", "Draw the lit part of the moon on the days of the phases (quarters) or every day (daily) (symbols)")
+var optSouthern = flag.Bool("south", false, "Draw the moon as seen from the southern hemisphere (false)")
+var optYearMoons = flag.Bool("yearmoon", false, "Draw the moon phases in the year calendars too (false)")
+var optStyle = flag.String("style", "", "Style XML file with the colors of the event categories")
+var optLegend = flag.Bool("legend", false, "Show a legend of the event categories (false)")
+var optShrink = flag.Bool("shrink", false, "Make the event text of crowded days smaller to fit (false)")
+var optAgenda = flag.Bool("agenda", false, "List the events that did not fit on a page after the month (false)")
+var optFields = flag.String("fields", "", "Columns of the CSV and JSON events, e.g. date=Start,text=Name,end=Until,category=Type,image=Photo")
+var optDateFormat = flag.String("dateformat", "", "Date format of the CSV and JSON events as Go layout, e.g. 02.01.2006 (as in XML)")
+var optExport = flag.String("export", "", "Also write the events to this ICS file (- for stdout)")
+var optExportMoon = flag.Bool("export-moon", false, "Add the moon phases to the ICS file of -export (false)")
+var optExportHolidays = flag.Bool("export-holidays", false, "Add the holidays to the ICS file of -export (false)")
+var optTags = flag.String("tags", "", "Show only events with one of the tags or categories, e.g. family,holiday")
+var optHolidays = flag.String("holidays", "", "Public holidays of a country or region, e.g. US, DE-BY (list to show all)")
 
 func main() {
 	flag.Var(&configFiles, "config", "Configuration XML files.")
 	flag.Var(&icsFiles, "ics", "Calendar ICS files.")
+	flag.Var(&csvFiles, "csv", "Event CSV files.")
+	flag.Var(&jsonFiles, "json", "Event JSON files.")
+	flag.Var(&vcardFiles, "vcard", "vCard files with birthdays and anniversaries.")
+	flag.Var(&excludeSources, "exclude-source", "Leave out the events of a config or ICS file, e.g. work.ics")
 	flag.Parse()
 
 	if *optVersion {
@@ -69,18 +138,28 @@ func main() {
 	}
 
 	wantyear := int(time.Now().Year())
+	endyear := wantyear
 	beginmonth := 1
 	endmonth := 12
 
-	if flag.NArg() == 1 {
+	if flag.NArg() == 1 && strings.Contains(flag.Arg(0), "..") {
+		var err error
+		wantyear, beginmonth, endyear, endmonth, err = parseRange(flag.Arg(0))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "# Error: %v\n", err)
+			os.Exit(1)
+		}
+	} else if flag.NArg() == 1 {
 		dummyyear, _ := strconv.ParseInt(flag.Arg(0), 10, 32)
 		wantyear = int(dummyyear)
+		endyear = wantyear
 	} else if flag.NArg() == 2 {
 		dummymonth, _ := strconv.ParseInt(flag.Arg(0), 10, 32)
 		dummyyear, _ := strconv.ParseInt(flag.Arg(1), 10, 32)
 		beginmonth = int(dummymonth)
 		endmonth = int(dummymonth)
 		wantyear = int(dummyyear)
+		endyear = wantyear
 	} else if flag.NArg() == 3 {
 		dummymonthBegin, _ := strconv.ParseInt(flag.Arg(0), 10, 32)
 		dummymonthEnd, _ := strconv.ParseInt(flag.Arg(1), 10, 32)
@@ -88,24 +167,100 @@ func main() {
 		beginmonth = int(dummymonthBegin)
 		endmonth = int(dummymonthEnd)
 		wantyear = int(dummyyear)
+		endyear = wantyear
 	}
 
-	g := gocal.New(beginmonth, endmonth, wantyear)
+	g := gocal.NewRange(wantyear, beginmonth, endyear, endmonth)
+	g.SetLogger(log.New(os.Stderr, "# ", 0))
 	g.SetFont(*optFont)
 	g.SetOrientation(*optOrientation)
 	g.SetPaperformat(*optPaper)
 	g.SetLocale(*optLocale)
 	g.SetYearSpread(*optYearSpread)
 	if *optYearSpread != 1 && (!*optYearA && !*optYearB) {
-		fmt.Printf("WARN: Option 'spread' ignored. Only valid for year-mode.\n")
+		fmt.Fprintf(os.Stderr, "WARN: Option 'spread' ignored. Only valid for year-mode.\n")
+	}
+	if *outfilename == "-" && *optExport == "-" {
+		fmt.Fprintf(os.Stderr, "# Error: -o and -export cannot both write to stdout\n")
+		os.Exit(1)
+	}
+	// The messages stay off stdout when a file is written to it.
+	status := os.Stdout
+	if *outfilename == "-" || *optExport == "-" {
+		status = os.Stderr
 	}
 
 	for _, i := range icsFiles {
 		g.AddICS(i)
 	}
+	g.SetCacheDir(*optCache)
+	if *optOffline == true {
+		g.SetOffline()
+	}
+	g.SetTimeout(*optTimeout)
+	g.SetTimezone(*optTimezone)
+	if *optTimes == true {
+		g.SetShowTimes()
+	}
+	if *optMoonTimes == true {
+		g.SetMoonTimes()
+	}
+	if *optMoonNames == true {
+		g.SetMoonNames()
+	}
+	if *optSouthern == true {
+		g.SetSouthern()
+	}
+	if *optYearMoons == true {
+		g.SetYearMoons()
+	}
+	switch *optMoonMode {
+	case "":
+	case "quarters", "daily":
+		g.SetMoonMode(*optMoonMode)
+	default:
+		fmt.Fprintf(os.Stderr, "# Error: unknown moon mode %q\n", *optMoonMode)
+		os.Exit(1)
+	}
 	for _, i := range configFiles {
 		g.AddConfig(i)
 	}
+	fields, err := gocal.ParseFieldMap(*optFields)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "# Error: -fields: %v\n", err)
+		os.Exit(1)
+	}
+	fields.DateFormat = *optDateFormat
+	for _, i := range csvFiles {
+		g.AddCSV(i, fields)
+	}
+	for _, i := range jsonFiles {
+		g.AddJSON(i, fields)
+	}
+	for _, i := range vcardFiles {
+		g.AddVCard(i)
+	}
+	g.SetStyle(*optStyle)
+	if *optLegend == true {
+		g.SetLegend()
+	}
+	if *optShrink == true {
+		g.SetShrink()
+	}
+	if *optAgenda == true {
+		g.SetAgenda()
+	}
+	if *optHideEvents == true {
+		g.SetHideEvents()
+	}
+	if *optTags != "" {
+		g.SetTags(strings.Split(*optTags, ",")...)
+	}
+	for _, s := range excludeSources {
+		for _, name := range strings.Split(s, ",") {
+			g.ExcludeSource(name)
+		}
+	}
 	if *optPlain == true {
 		g.SetPlain()
 	}
@@ -134,17 +289,85 @@ func main() {
 	g.SetFooter(*optFooter)
 	g.SetMargin(*optMargin)
 	g.SetFillpattern(*optFillpattern)
+	if *optFirstday != "" {
+		wd, err := parseWeekday(*optFirstday)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "# Error: %v\n", err)
+			os.Exit(1)
+		}
+		g.SetFirstWeekday(wd)
+	}
+	switch *optDirection {
+	case "":
+	case "rtl", "ltr":
+		g.SetDirection(*optDirection)
+	default:
+		fmt.Fprintf(os.Stderr, "# Error: unknown direction %q\n", *optDirection)
+		os.Exit(1)
+	}
+	if *optSecondary != "" {
+		p := gocal.SecondaryCalendar(*optSecondary)
+		if p == nil {
+			fmt.Fprintf(os.Stderr, "# Error: unknown calendar %q\n", *optSecondary)
+			os.Exit(1)
+		}
+		g.SetSecondary(p)
+	}
+	if *optHolidays == "list" {
+		fmt.Println(strings.Join(gocal.HolidayRegions(), " "))
+		os.Exit(0)
+	}
+	g.SetHolidays(*optHolidays)
 	/*
 	  // How to create an event:
 	  g.AddEvent(31, 1, "one", "")
 	  g.AddEvent(28, 2, "two", "")
 	  g.AddEvent(31, 3, "three", "")
 	*/
-	if *optYearA == true {
-		g.CreateYearCalendar(*outfilename)
-	} else if *optYearB == true {
-		g.CreateYearCalendarInverse(*outfilename)
+	if *outfilename == "-" {
+		if *optYearA == true {
+			err = g.CreateYearCalendarTo(os.Stdout)
+		} else if *optYearB == true {
+			err = g.CreateYearCalendarInverseTo(os.Stdout)
+		} else {
+			err = g.CreateCalendarTo(os.Stdout)
+		}
 	} else {
-		g.CreateCalendar(*outfilename)
+		if *optYearA == true {
+			err = g.CreateYearCalendar(*outfilename)
+		} else if *optYearB == true {
+			err = g.CreateYearCalendarInverse(*outfilename)
+		} else {
+			err = g.CreateCalendar(*outfilename)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "# Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *outfilename != "-" {
+		fmt.Fprintf(status, "Generated '%v'.\n", *outfilename)
+	}
+
+	if *optExport == "" {
+		return
+	}
+	if *optExportMoon == true {
+		g.SetExportMoon()
+	}
+	if *optExportHolidays == true {
+		g.SetExportHolidays()
+	}
+	if *optExport == "-" {
+		err = g.CreateICSTo(os.Stdout)
+	} else {
+		err = g.CreateICS(*optExport)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "# Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *optExport != "-" {
+		fmt.Fprintf(status, "Generated '%v'.\n", *optExport)
 	}
 }
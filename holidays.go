@@ -0,0 +1,994 @@
+package gocal
+
+// Copyright (c) 2014 Stefan Schroeder, NY, 2014-03-10
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file
+//
+// holidays.go
+//
+// This file is part of gocal, a PDF calendar generator in Go.
+//
+// https://github.com/StefanSchroeder/Gocal
+//
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/soniakeys/meeus/v3/base"
+	"github.com/soniakeys/meeus/v3/easter"
+	"github.com/soniakeys/meeus/v3/julian"
+	"github.com/soniakeys/meeus/v3/solar"
+	"github.com/soniakeys/meeus/v3/solstice"
+)
+
+// holidayDate is one public holiday.
+type holidayDate struct {
+	date time.Time
+	name string
+}
+
+// holidayRule yields the dates of a holiday in a year. The holiday
+// is kept in the regions, a space-separated list, or everywhere if
+// there is none, and in the years from until until, where 0 means
+// no limit. An observed rule moves a holiday that falls on a free
+// day to a substitute day.
+type holidayRule struct {
+	name    string
+	dates   func(y int) []time.Time
+	observe func(t time.Time, taken map[time.Time]bool) time.Time
+	regions string
+	from    int
+	until   int
+}
+
+// holidayCountry is the set of holidays of a country. Observed is the
+// format of the name of a substitute day, regions lists the valid
+// regions and between, if set, names a day between two holidays,
+// which is a holiday as well.
+type holidayCountry struct {
+	rules    []holidayRule
+	observed string
+	regions  string
+	between  string
+}
+
+// holiday is the rule of the holiday name on the dates.
+func holiday(name string, dates func(y int) []time.Time) holidayRule {
+	return holidayRule{name, dates, nil, "", 0, 0}
+}
+
+func (r holidayRule) in(regions string) holidayRule {
+	r.regions = regions
+	return r
+}
+
+func (r holidayRule) since(y int) holidayRule {
+	r.from = y
+	return r
+}
+
+func (r holidayRule) till(y int) holidayRule {
+	r.until = y
+	return r
+}
+
+func (r holidayRule) observed(f func(time.Time, map[time.Time]bool) time.Time) holidayRule {
+	r.observe = f
+	return r
+}
+
+// applies tells if the rule holds in the year y and the region.
+func (r holidayRule) applies(y int, region string) bool {
+	if (r.from != 0 && y < r.from) || (r.until != 0 && y > r.until) {
+		return false
+	}
+	if r.regions == "" {
+		return true
+	}
+	for _, reg := range strings.Fields(r.regions) {
+		if reg == region {
+			return true
+		}
+	}
+	return false
+}
+
+// utcDate is the day d of the month m of the year y.
+func utcDate(y int, m int, d int) time.Time {
+	return time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.UTC)
+}
+
+// jdnTime returns the day of the Julian day number n.
+func jdnTime(n int) time.Time {
+	y, m, d := julian.JDToCalendar(float64(n))
+	return utcDate(y, m, int(d))
+}
+
+// fixedDay is a holiday on the day d of the month m.
+func fixedDay(m int, d int) func(int) []time.Time {
+	return func(y int) []time.Time {
+		return []time.Time{utcDate(y, m, d)}
+	}
+}
+
+// nthWeekday is a holiday on the n-th weekday wd of the month m.
+// For n < 0 the weekdays are counted from the end of the month.
+func nthWeekday(n int, wd time.Weekday, m int) func(int) []time.Time {
+	return func(y int) []time.Time {
+		if n < 0 {
+			t := utcDate(y, m+1, 0)
+			t = t.AddDate(0, 0, -((int(t.Weekday())-int(wd)+7)%7)+7*(n+1))
+			return []time.Time{t}
+		}
+		t := utcDate(y, m, 1)
+		t = t.AddDate(0, 0, (int(wd)-int(t.Weekday())+7)%7+7*(n-1))
+		return []time.Time{t}
+	}
+}
+
+// onOrAfter is a holiday on the first weekday wd on or after the
+// day d of the month m.
+func onOrAfter(wd time.Weekday, m int, d int) func(int) []time.Time {
+	return func(y int) []time.Time {
+		t := utcDate(y, m, d)
+		return []time.Time{t.AddDate(0, 0, (int(wd)-int(t.Weekday())+7)%7)}
+	}
+}
+
+// easterDay is a holiday off days after Easter Sunday.
+func easterDay(off int) func(int) []time.Time {
+	return func(y int) []time.Time {
+		m, d := easter.Gregorian(y)
+		return []time.Time{utcDate(y, m, d+off)}
+	}
+}
+
+// orthodoxDay is a holiday off days after the Orthodox Easter.
+func orthodoxDay(off int) func(int) []time.Time {
+	return func(y int) []time.Time {
+		m, d := easter.Julian(y)
+		n := int(julian.CalendarJulianToJD(y, m, float64(d)) + 0.5)
+		return []time.Time{jdnTime(n + off)}
+	}
+}
+
+// lunarDay is a holiday off days after the day d of the month m of
+// the Chinese calendar.
+func lunarDay(m int, d int, off int) func(int) []time.Time {
+	return func(y int) []time.Time {
+		return []time.Time{jdnTime(jdnFromChinese(y, m, d) + off)}
+	}
+}
+
+// hijriDay is a holiday on the day d of the month m of the tabular
+// Islamic calendar. It may occur twice in a year.
+func hijriDay(m int, d int) func(int) []time.Time {
+	return func(y int) (out []time.Time) {
+		first, _, _ := hijriFromJDN(jdn(utcDate(y, 1, 1)))
+		for hy := first; hy <= first+2; hy++ {
+			t := jdnTime(jdnFromHijri(hy, m, d))
+			if t.Year() > y {
+				break
+			}
+			if t.Year() == y {
+				out = append(out, t)
+			}
+		}
+		return out
+	}
+}
+
+// sunLongitude returns the apparent longitude of the sun in degrees
+// at the beginning of the day n in the time zone hours ahead of UTC.
+func sunLongitude(n int, hours float64) float64 {
+	jde := float64(n) - 0.5 - hours/24
+	jde += deltaT(jde)
+	lon := math.Mod(solar.ApparentLongitude(base.J2000Century(jde)).Deg(), 360)
+	if lon < 0 {
+		lon += 360
+	}
+	return lon
+}
+
+// qingming is the day of the solar term Qingming, when the sun
+// reaches the longitude of 15° in China.
+func qingming(y int) []time.Time {
+	n := jdn(utcDate(y, 4, 1))
+	for sunLongitude(n+1, 8) < 15 {
+		n++
+	}
+	return []time.Time{jdnTime(n)}
+}
+
+// equinoxJapan is the day of the March or September equinox in Japan.
+func equinoxJapan(m int) func(int) []time.Time {
+	return func(y int) []time.Time {
+		if m == 3 {
+			return []time.Time{jdnTime(localDay(solstice.March(y), 9))}
+		}
+		return []time.Time{jdnTime(localDay(solstice.September(y), 9))}
+	}
+}
+
+// nearestWeekday moves a holiday on Saturday to Friday and one on
+// Sunday to Monday.
+func nearestWeekday(t time.Time, taken map[time.Time]bool) time.Time {
+	switch t.Weekday() {
+	case time.Saturday:
+		return t.AddDate(0, 0, -1)
+	case time.Sunday:
+		return t.AddDate(0, 0, 1)
+	}
+	return t
+}
+
+// nextWorkday moves a holiday on the weekend to the next weekday
+// that is not a holiday.
+func nextWorkday(t time.Time, taken map[time.Time]bool) time.Time {
+	if t.Weekday() != time.Saturday && t.Weekday() != time.Sunday {
+		return t
+	}
+	for t.Weekday() == time.Saturday || t.Weekday() == time.Sunday || taken[t] {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t
+}
+
+// sundayNext moves a holiday on Sunday to the next day that is not
+// a holiday.
+func sundayNext(t time.Time, taken map[time.Time]bool) time.Time {
+	if t.Weekday() != time.Sunday {
+		return t
+	}
+	for t.Weekday() == time.Sunday || taken[t] {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t
+}
+
+// observedSince limits the observed rule f to the years since y.
+func observedSince(y int, f func(time.Time, map[time.Time]bool) time.Time) func(time.Time, map[time.Time]bool) time.Time {
+	return func(t time.Time, taken map[time.Time]bool) time.Time {
+		if t.Year() < y {
+			return t
+		}
+		return f(t, taken)
+	}
+}
+
+// year returns the holidays of the year y in the region.
+func (c holidayCountry) year(y int, region string) []holidayDate {
+	type day struct {
+		holidayDate
+		observe func(time.Time, map[time.Time]bool) time.Time
+	}
+	var days []day
+	taken := make(map[time.Time]bool)
+	for _, r := range c.rules {
+		if !r.applies(y, region) {
+			continue
+		}
+		for _, t := range r.dates(y) {
+			days = append(days, day{holidayDate{t, r.name}, r.observe})
+			taken[t] = true
+		}
+	}
+	sort.SliceStable(days, func(i, j int) bool { return days[i].date.Before(days[j].date) })
+
+	var out []holidayDate
+	for _, d := range days {
+		out = append(out, d.holidayDate)
+		if d.observe == nil {
+			continue
+		}
+		if o := d.observe(d.date, taken); !o.Equal(d.date) {
+			taken[o] = true
+			out = append(out, holidayDate{o, fmt.Sprintf(c.observed, d.name)})
+		}
+	}
+	if c.between != "" {
+		for t := range taken {
+			b := t.AddDate(0, 0, 1)
+			if taken[b.AddDate(0, 0, 1)] && !taken[b] && b.Weekday() != time.Sunday && b.Year() == y {
+				out = append(out, holidayDate{b, c.between})
+			}
+		}
+	}
+	return out
+}
+
+// holidays returns the public holidays from the day from to the day
+// to in the region, which is a country code like DE or a country
+// code and a region like DE-BY.
+func holidays(region string, from, to time.Time) ([]holidayDate, error) {
+	code, sub := strings.ToUpper(region), ""
+	if i := strings.Index(code, "-"); i != -1 {
+		code, sub = code[:i], code[i+1:]
+	}
+	c, ok := holidayCountries[code]
+	if !ok {
+		return nil, &Error{ErrHolidays, region, nil}
+	}
+	if sub != "" && !strings.Contains(" "+c.regions+" ", " "+sub+" ") {
+		return nil, &Error{ErrHolidays, region, nil}
+	}
+
+	var out []holidayDate
+	for y := from.Year() - 1; y <= to.Year()+1; y++ {
+		for _, d := range c.year(y, sub) {
+			if !d.date.Before(from) && !d.date.After(to) {
+				out = append(out, d)
+			}
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].date.Before(out[j].date) })
+	return out, nil
+}
+
+// HolidayRegions returns the country codes and their regions for
+// which holidays are known, e.g. "DE" and "DE-BY".
+func HolidayRegions() (out []string) {
+	for code, c := range holidayCountries {
+		out = append(out, code)
+		for _, reg := range strings.Fields(c.regions) {
+			out = append(out, code+"-"+reg)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// The French holidays, also in the overseas departments.
+var frenchHolidays = []holidayRule{
+	holiday("Jour de l'an", fixedDay(1, 1)),
+	holiday("Vendredi saint", easterDay(-2)).in("57 67 68"),
+	holiday("Lundi de Pâques", easterDay(1)),
+	holiday("Fête du Travail", fixedDay(5, 1)),
+	holiday("Victoire 1945", fixedDay(5, 8)),
+	holiday("Ascension", easterDay(39)),
+	holiday("Lundi de Pentecôte", easterDay(50)),
+	holiday("Fête nationale", fixedDay(7, 14)),
+	holiday("Assomption", fixedDay(8, 15)),
+	holiday("Toussaint", fixedDay(11, 1)),
+	holiday("Armistice 1918", fixedDay(11, 11)),
+	holiday("Noël", fixedDay(12, 25)),
+	holiday("Saint-Étienne", fixedDay(12, 26)).in("57 67 68"),
+}
+
+func withFrench(rules ...holidayRule) []holidayRule {
+	return append(append([]holidayRule{}, frenchHolidays...), rules...)
+}
+
+// holidayCountries holds the holidays by country code. The names
+// are in the language of the country, except where the built-in
+// fonts do not have its script.
+var holidayCountries = map[string]holidayCountry{
+	"US": {[]holidayRule{
+		holiday("New Year's Day", fixedDay(1, 1)).observed(nearestWeekday),
+		holiday("Martin Luther King Jr. Day", nthWeekday(3, time.Monday, 1)).since(1986),
+		holiday("Washington's Birthday", nthWeekday(3, time.Monday, 2)),
+		holiday("Memorial Day", nthWeekday(-1, time.Monday, 5)),
+		holiday("Juneteenth", fixedDay(6, 19)).since(2021).observed(nearestWeekday),
+		holiday("Independence Day", fixedDay(7, 4)).observed(nearestWeekday),
+		holiday("Labor Day", nthWeekday(1, time.Monday, 9)),
+		holiday("Columbus Day", nthWeekday(2, time.Monday, 10)),
+		holiday("Veterans Day", fixedDay(11, 11)).observed(nearestWeekday),
+		holiday("Thanksgiving Day", nthWeekday(4, time.Thursday, 11)),
+		holiday("Christmas Day", fixedDay(12, 25)).observed(nearestWeekday),
+	}, "%s (observed)", "", ""},
+
+	"GB": {[]holidayRule{
+		holiday("New Year's Day", fixedDay(1, 1)).observed(nextWorkday),
+		holiday("2nd January", fixedDay(1, 2)).in("SCT").observed(nextWorkday),
+		holiday("St Patrick's Day", fixedDay(3, 17)).in("NIR").observed(nextWorkday),
+		holiday("Good Friday", easterDay(-2)),
+		holiday("Easter Monday", easterDay(1)).in("ENG WLS NIR"),
+		holiday("Early May bank holiday", nthWeekday(1, time.Monday, 5)),
+		holiday("Spring bank holiday", nthWeekday(-1, time.Monday, 5)),
+		holiday("Battle of the Boyne", fixedDay(7, 12)).in("NIR").observed(nextWorkday),
+		holiday("Summer bank holiday", nthWeekday(1, time.Monday, 8)).in("SCT"),
+		holiday("Summer bank holiday", nthWeekday(-1, time.Monday, 8)).in("ENG WLS NIR"),
+		holiday("St Andrew's Day", fixedDay(11, 30)).in("SCT").observed(nextWorkday),
+		holiday("Christmas Day", fixedDay(12, 25)).observed(nextWorkday),
+		holiday("Boxing Day", fixedDay(12, 26)).observed(nextWorkday),
+	}, "%s (substitute day)", "ENG WLS SCT NIR", ""},
+
+	"DK": {[]holidayRule{
+		holiday("Nytårsdag", fixedDay(1, 1)),
+		holiday("Skærtorsdag", easterDay(-3)),
+		holiday("Langfredag", easterDay(-2)),
+		holiday("Påskedag", easterDay(0)),
+		holiday("2. påskedag", easterDay(1)),
+		holiday("Store bededag", easterDay(26)).till(2023),
+		holiday("Kristi himmelfartsdag", easterDay(39)),
+		holiday("Pinsedag", easterDay(49)),
+		holiday("2. pinsedag", easterDay(50)),
+		holiday("Grundlovsdag", fixedDay(6, 5)),
+		holiday("Juleaftensdag", fixedDay(12, 24)),
+		holiday("Juledag", fixedDay(12, 25)),
+		holiday("2. juledag", fixedDay(12, 26)),
+	}, "%s", "", ""},
+
+	"BE": {[]holidayRule{
+		holiday("Nieuwjaar", fixedDay(1, 1)),
+		holiday("Pasen", easterDay(0)),
+		holiday("Paasmaandag", easterDay(1)),
+		holiday("Dag van de Arbeid", fixedDay(5, 1)),
+		holiday("O.L.H. Hemelvaart", easterDay(39)),
+		holiday("Pinksteren", easterDay(49)),
+		holiday("Pinkstermaandag", easterDay(50)),
+		holiday("Nationale feestdag", fixedDay(7, 21)),
+		holiday("O.L.V. Hemelvaart", fixedDay(8, 15)),
+		holiday("Allerheiligen", fixedDay(11, 1)),
+		holiday("Wapenstilstand", fixedDay(11, 11)),
+		holiday("Kerstmis", fixedDay(12, 25)),
+	}, "%s", "", ""},
+
+	"NL": {[]holidayRule{
+		holiday("Nieuwjaarsdag", fixedDay(1, 1)),
+		holiday("Goede Vrijdag", easterDay(-2)),
+		holiday("Eerste paasdag", easterDay(0)),
+		holiday("Tweede paasdag", easterDay(1)),
+		holiday("Koningsdag", func(y int) []time.Time {
+			if t := utcDate(y, 4, 27); t.Weekday() != time.Sunday {
+				return []time.Time{t}
+			}
+			return []time.Time{utcDate(y, 4, 26)}
+		}).since(2014),
+		holiday("Koninginnedag", func(y int) []time.Time {
+			if t := utcDate(y, 4, 30); t.Weekday() != time.Sunday {
+				return []time.Time{t}
+			}
+			return []time.Time{utcDate(y, 4, 29)}
+		}).till(2013),
+		holiday("Bevrijdingsdag", fixedDay(5, 5)),
+		holiday("Hemelvaartsdag", easterDay(39)),
+		holiday("Eerste pinksterdag", easterDay(49)),
+		holiday("Tweede pinksterdag", easterDay(50)),
+		holiday("Eerste kerstdag", fixedDay(12, 25)),
+		holiday("Tweede kerstdag", fixedDay(12, 26)),
+	}, "%s", "", ""},
+
+	"FI": {[]holidayRule{
+		holiday("Uudenvuodenpäivä", fixedDay(1, 1)),
+		holiday("Loppiainen", fixedDay(1, 6)),
+		holiday("Pitkäperjantai", easterDay(-2)),
+		holiday("Pääsiäispäivä", easterDay(0)),
+		holiday("2. pääsiäispäivä", easterDay(1)),
+		holiday("Vappu", fixedDay(5, 1)),
+		holiday("Helatorstai", easterDay(39)),
+		holiday("Helluntaipäivä", easterDay(49)),
+		holiday("Juhannusaatto", onOrAfter(time.Friday, 6, 19)),
+		holiday("Juhannuspäivä", onOrAfter(time.Saturday, 6, 20)),
+		holiday("Pyhäinpäivä", onOrAfter(time.Saturday, 10, 31)),
+		holiday("Itsenäisyyspäivä", fixedDay(12, 6)),
+		holiday("Jouluaatto", fixedDay(12, 24)),
+		holiday("Joulupäivä", fixedDay(12, 25)),
+		holiday("Tapaninpäivä", fixedDay(12, 26)),
+	}, "%s", "", ""},
+
+	// Alsace-Moselle are the departments 57, 67 and 68.
+	"FR": {frenchHolidays, "%s", "57 67 68", ""},
+	"GP": {withFrench(holiday("Abolition de l'esclavage", fixedDay(5, 27))), "%s", "", ""},
+	"MQ": {withFrench(holiday("Abolition de l'esclavage", fixedDay(5, 22))), "%s", "", ""},
+	"GF": {withFrench(holiday("Abolition de l'esclavage", fixedDay(6, 10))), "%s", "", ""},
+	"RE": {withFrench(holiday("Abolition de l'esclavage", fixedDay(12, 20))), "%s", "", ""},
+
+	"LU": {[]holidayRule{
+		holiday("Jour de l'an", fixedDay(1, 1)),
+		holiday("Lundi de Pâques", easterDay(1)),
+		holiday("Fête du Travail", fixedDay(5, 1)),
+		holiday("Journée de l'Europe", fixedDay(5, 9)).since(2019),
+		holiday("Ascension", easterDay(39)),
+		holiday("Lundi de Pentecôte", easterDay(50)),
+		holiday("Fête nationale", fixedDay(6, 23)),
+		holiday("Assomption", fixedDay(8, 15)),
+		holiday("Toussaint", fixedDay(11, 1)),
+		holiday("Noël", fixedDay(12, 25)),
+		holiday("Saint-Étienne", fixedDay(12, 26)),
+	}, "%s", "", ""},
+
+	"CA": {[]holidayRule{
+		holiday("New Year's Day", fixedDay(1, 1)).observed(nextWorkday),
+		holiday("Family Day", nthWeekday(3, time.Monday, 2)).in("AB BC NB ON SK"),
+		holiday("Louis Riel Day", nthWeekday(3, time.Monday, 2)).in("MB"),
+		holiday("Islander Day", nthWeekday(3, time.Monday, 2)).in("PE"),
+		holiday("Heritage Day", nthWeekday(3, time.Monday, 2)).in("NS"),
+		holiday("Good Friday", easterDay(-2)),
+		holiday("Easter Monday", easterDay(1)),
+		holiday("Victoria Day", onOrAfter(time.Monday, 5, 18)),
+		holiday("Fête nationale du Québec", fixedDay(6, 24)).in("QC"),
+		holiday("Canada Day", fixedDay(7, 1)).observed(nextWorkday),
+		holiday("Civic Holiday", nthWeekday(1, time.Monday, 8)),
+		holiday("Labour Day", nthWeekday(1, time.Monday, 9)),
+		holiday("National Day for Truth and Reconciliation", fixedDay(9, 30)).since(2021),
+		holiday("Thanksgiving", nthWeekday(2, time.Monday, 10)),
+		holiday("Remembrance Day", fixedDay(11, 11)),
+		holiday("Christmas Day", fixedDay(12, 25)).observed(nextWorkday),
+		holiday("Boxing Day", fixedDay(12, 26)).observed(nextWorkday),
+	}, "%s (observed)", "AB BC MB NB NL NS NT NU ON PE QC SK YT", ""},
+
+	"DE": {[]holidayRule{
+		holiday("Neujahr", fixedDay(1, 1)),
+		holiday("Heilige Drei Könige", fixedDay(1, 6)).in("BW BY ST"),
+		holiday("Frauentag", fixedDay(3, 8)).in("BE").since(2019),
+		holiday("Frauentag", fixedDay(3, 8)).in("MV").since(2023),
+		holiday("Karfreitag", easterDay(-2)),
+		holiday("Ostersonntag", easterDay(0)).in("BB"),
+		holiday("Ostermontag", easterDay(1)),
+		holiday("Tag der Arbeit", fixedDay(5, 1)),
+		holiday("Christi Himmelfahrt", easterDay(39)),
+		holiday("Pfingstsonntag", easterDay(49)).in("BB"),
+		holiday("Pfingstmontag", easterDay(50)),
+		holiday("Fronleichnam", easterDay(60)).in("BW BY HE NW RP SL"),
+		holiday("Mariä Himmelfahrt", fixedDay(8, 15)).in("BY SL"),
+		holiday("Weltkindertag", fixedDay(9, 20)).in("TH").since(2019),
+		holiday("Tag der Deutschen Einheit", fixedDay(10, 3)),
+		holiday("Reformationstag", fixedDay(10, 31)).in("BB MV SN ST TH"),
+		holiday("Reformationstag", fixedDay(10, 31)).in("HB HH NI SH").since(2018),
+		holiday("Reformationstag", fixedDay(10, 31)).since(2017).till(2017),
+		holiday("Allerheiligen", fixedDay(11, 1)).in("BW BY NW RP SL"),
+		holiday("Buß- und Bettag", onOrAfter(time.Wednesday, 11, 16)).in("SN"),
+		holiday("1. Weihnachtstag", fixedDay(12, 25)),
+		holiday("2. Weihnachtstag", fixedDay(12, 26)),
+	}, "%s", "BW BY BE BB HB HH HE MV NI NW RP SL SN ST SH TH", ""},
+
+	"HU": {[]holidayRule{
+		holiday("Újév", fixedDay(1, 1)),
+		holiday("Nemzeti ünnep", fixedDay(3, 15)),
+		holiday("Nagypéntek", easterDay(-2)).since(2017),
+		holiday("Húsvétvasárnap", easterDay(0)),
+		holiday("Húsvéthétfő", easterDay(1)),
+		holiday("A munka ünnepe", fixedDay(5, 1)),
+		holiday("Pünkösdvasárnap", easterDay(49)),
+		holiday("Pünkösdhétfő", easterDay(50)),
+		holiday("Az államalapítás ünnepe", fixedDay(8, 20)),
+		holiday("Nemzeti ünnep", fixedDay(10, 23)),
+		holiday("Mindenszentek", fixedDay(11, 1)),
+		holiday("Karácsony", fixedDay(12, 25)),
+		holiday("Karácsony másnapja", fixedDay(12, 26)),
+	}, "%s", "", ""},
+
+	"IT": {[]holidayRule{
+		holiday("Capodanno", fixedDay(1, 1)),
+		holiday("Epifania", fixedDay(1, 6)),
+		holiday("Pasqua", easterDay(0)),
+		holiday("Lunedì dell'Angelo", easterDay(1)),
+		holiday("Festa della Liberazione", fixedDay(4, 25)),
+		holiday("Festa del Lavoro", fixedDay(5, 1)),
+		holiday("Festa della Repubblica", fixedDay(6, 2)),
+		holiday("Ferragosto", fixedDay(8, 15)),
+		holiday("Ognissanti", fixedDay(11, 1)),
+		holiday("Immacolata Concezione", fixedDay(12, 8)),
+		holiday("Natale", fixedDay(12, 25)),
+		holiday("Santo Stefano", fixedDay(12, 26)),
+	}, "%s", "", ""},
+
+	"NO": {[]holidayRule{
+		holiday("Første nyttårsdag", fixedDay(1, 1)),
+		holiday("Skjærtorsdag", easterDay(-3)),
+		holiday("Langfredag", easterDay(-2)),
+		holiday("Første påskedag", easterDay(0)),
+		holiday("Andre påskedag", easterDay(1)),
+		holiday("Arbeidernes dag", fixedDay(5, 1)),
+		holiday("Grunnlovsdag", fixedDay(5, 17)),
+		holiday("Kristi himmelfartsdag", easterDay(39)),
+		holiday("Første pinsedag", easterDay(49)),
+		holiday("Andre pinsedag", easterDay(50)),
+		holiday("Første juledag", fixedDay(12, 25)),
+		holiday("Andre juledag", fixedDay(12, 26)),
+	}, "%s", "", ""},
+
+	"PL": {[]holidayRule{
+		holiday("Nowy Rok", fixedDay(1, 1)),
+		holiday("Trzech Króli", fixedDay(1, 6)).since(2011),
+		holiday("Wielkanoc", easterDay(0)),
+		holiday("Poniedziałek Wielkanocny", easterDay(1)),
+		holiday("Święto Pracy", fixedDay(5, 1)),
+		holiday("Święto Konstytucji 3 Maja", fixedDay(5, 3)),
+		holiday("Zielone Świątki", easterDay(49)),
+		holiday("Boże Ciało", easterDay(60)),
+		holiday("Wniebowzięcie NMP", fixedDay(8, 15)),
+		holiday("Wszystkich Świętych", fixedDay(11, 1)),
+		holiday("Święto Niepodległości", fixedDay(11, 11)),
+		holiday("Wigilia", fixedDay(12, 24)).since(2025),
+		holiday("Boże Narodzenie", fixedDay(12, 25)),
+		holiday("Drugi dzień Bożego Narodzenia", fixedDay(12, 26)),
+	}, "%s", "", ""},
+
+	"PT": {[]holidayRule{
+		holiday("Ano Novo", fixedDay(1, 1)),
+		holiday("Sexta-feira Santa", easterDay(-2)),
+		holiday("Páscoa", easterDay(0)),
+		holiday("Dia da Liberdade", fixedDay(4, 25)),
+		holiday("Dia do Trabalhador", fixedDay(5, 1)),
+		holiday("Corpo de Deus", easterDay(60)),
+		holiday("Dia de Portugal", fixedDay(6, 10)),
+		holiday("Assunção de Nossa Senhora", fixedDay(8, 15)),
+		holiday("Implantação da República", fixedDay(10, 5)),
+		holiday("Todos os Santos", fixedDay(11, 1)),
+		holiday("Restauração da Independência", fixedDay(12, 1)),
+		holiday("Imaculada Conceição", fixedDay(12, 8)),
+		holiday("Natal", fixedDay(12, 25)),
+	}, "%s", "", ""},
+
+	"BR": {[]holidayRule{
+		holiday("Confraternização Universal", fixedDay(1, 1)),
+		holiday("Carnaval", easterDay(-48)),
+		holiday("Carnaval", easterDay(-47)),
+		holiday("Sexta-feira Santa", easterDay(-2)),
+		holiday("Tiradentes", fixedDay(4, 21)),
+		holiday("Dia do Trabalho", fixedDay(5, 1)),
+		holiday("Corpus Christi", easterDay(60)),
+		holiday("Independência do Brasil", fixedDay(9, 7)),
+		holiday("Nossa Senhora Aparecida", fixedDay(10, 12)),
+		holiday("Finados", fixedDay(11, 2)),
+		holiday("Proclamação da República", fixedDay(11, 15)),
+		holiday("Dia da Consciência Negra", fixedDay(11, 20)).since(2024),
+		holiday("Natal", fixedDay(12, 25)),
+	}, "%s", "", ""},
+
+	"RO": {[]holidayRule{
+		holiday("Anul Nou", fixedDay(1, 1)),
+		holiday("Anul Nou", fixedDay(1, 2)),
+		holiday("Boboteaza", fixedDay(1, 6)).since(2024),
+		holiday("Sfântul Ioan Botezătorul", fixedDay(1, 7)).since(2024),
+		holiday("Ziua Unirii Principatelor Române", fixedDay(1, 24)),
+		holiday("Vinerea Mare", orthodoxDay(-2)),
+		holiday("Paștele", orthodoxDay(0)),
+		holiday("Paștele", orthodoxDay(1)),
+		holiday("Ziua Muncii", fixedDay(5, 1)),
+		holiday("Ziua Copilului", fixedDay(6, 1)).since(2017),
+		holiday("Rusaliile", orthodoxDay(49)),
+		holiday("Rusaliile", orthodoxDay(50)),
+		holiday("Adormirea Maicii Domnului", fixedDay(8, 15)),
+		holiday("Sfântul Andrei", fixedDay(11, 30)),
+		holiday("Ziua Națională", fixedDay(12, 1)),
+		holiday("Crăciunul", fixedDay(12, 25)),
+		holiday("Crăciunul", fixedDay(12, 26)),
+	}, "%s", "", ""},
+
+	"RU": {[]holidayRule{
+		holiday("Новогодние каникулы", fixedDay(1, 1)),
+		holiday("Новогодние каникулы", fixedDay(1, 2)),
+		holiday("Новогодние каникулы", fixedDay(1, 3)),
+		holiday("Новогодние каникулы", fixedDay(1, 4)),
+		holiday("Новогодние каникулы", fixedDay(1, 5)),
+		holiday("Новогодние каникулы", fixedDay(1, 6)),
+		holiday("Рождество Христово", fixedDay(1, 7)),
+		holiday("Новогодние каникулы", fixedDay(1, 8)),
+		holiday("День защитника Отечества", fixedDay(2, 23)).observed(nextWorkday),
+		holiday("Международный женский день", fixedDay(3, 8)).observed(nextWorkday),
+		holiday("Праздник Весны и Труда", fixedDay(5, 1)).observed(nextWorkday),
+		holiday("День Победы", fixedDay(5, 9)).observed(nextWorkday),
+		holiday("День России", fixedDay(6, 12)).observed(nextWorkday),
+		holiday("День народного единства", fixedDay(11, 4)).observed(nextWorkday),
+	}, "%s (выходной)", "", ""},
+
+	"ES": {[]holidayRule{
+		holiday("Año Nuevo", fixedDay(1, 1)),
+		holiday("Epifanía del Señor", fixedDay(1, 6)),
+		holiday("Día de Andalucía", fixedDay(2, 28)).in("AN"),
+		holiday("Jueves Santo", easterDay(-3)).in("AN MD"),
+		holiday("Viernes Santo", easterDay(-2)),
+		holiday("Dilluns de Pasqua Florida", easterDay(1)).in("CT"),
+		holiday("Fiesta del Trabajo", fixedDay(5, 1)),
+		holiday("Fiesta de la Comunidad de Madrid", fixedDay(5, 2)).in("MD"),
+		holiday("Sant Joan", fixedDay(6, 24)).in("CT"),
+		holiday("Asunción de la Virgen", fixedDay(8, 15)),
+		holiday("Diada Nacional de Catalunya", fixedDay(9, 11)).in("CT"),
+		holiday("Fiesta Nacional de España", fixedDay(10, 12)),
+		holiday("Todos los Santos", fixedDay(11, 1)),
+		holiday("Día de la Constitución", fixedDay(12, 6)),
+		holiday("Inmaculada Concepción", fixedDay(12, 8)),
+		holiday("Navidad", fixedDay(12, 25)),
+		holiday("Sant Esteve", fixedDay(12, 26)).in("CT"),
+	}, "%s", "AN CT MD", ""},
+
+	"SE": {[]holidayRule{
+		holiday("Nyårsdagen", fixedDay(1, 1)),
+		holiday("Trettondedag jul", fixedDay(1, 6)),
+		holiday("Långfredagen", easterDay(-2)),
+		holiday("Påskdagen", easterDay(0)),
+		holiday("Annandag påsk", easterDay(1)),
+		holiday("Första maj", fixedDay(5, 1)),
+		holiday("Kristi himmelsfärdsdag", easterDay(39)),
+		holiday("Pingstdagen", easterDay(49)),
+		holiday("Sveriges nationaldag", fixedDay(6, 6)),
+		holiday("Midsommarafton", onOrAfter(time.Friday, 6, 19)),
+		holiday("Midsommardagen", onOrAfter(time.Saturday, 6, 20)),
+		holiday("Alla helgons dag", onOrAfter(time.Saturday, 10, 31)),
+		holiday("Julafton", fixedDay(12, 24)),
+		holiday("Juldagen", fixedDay(12, 25)),
+		holiday("Annandag jul", fixedDay(12, 26)),
+		holiday("Nyårsafton", fixedDay(12, 31)),
+	}, "%s", "", ""},
+
+	"TR": {[]holidayRule{
+		holiday("Yılbaşı", fixedDay(1, 1)),
+		holiday("Ulusal Egemenlik ve Çocuk Bayramı", fixedDay(4, 23)),
+		holiday("Emek ve Dayanışma Günü", fixedDay(5, 1)),
+		holiday("Atatürk'ü Anma, Gençlik ve Spor Bayramı", fixedDay(5, 19)),
+		holiday("Demokrasi ve Milli Birlik Günü", fixedDay(7, 15)).since(2017),
+		holiday("Zafer Bayramı", fixedDay(8, 30)),
+		holiday("Cumhuriyet Bayramı", fixedDay(10, 29)),
+		holiday("Ramazan Bayramı", hijriDay(10, 1)),
+		holiday("Ramazan Bayramı", hijriDay(10, 2)),
+		holiday("Ramazan Bayramı", hijriDay(10, 3)),
+		holiday("Kurban Bayramı", hijriDay(12, 10)),
+		holiday("Kurban Bayramı", hijriDay(12, 11)),
+		holiday("Kurban Bayramı", hijriDay(12, 12)),
+		holiday("Kurban Bayramı", hijriDay(12, 13)),
+	}, "%s", "", ""},
+
+	"UA": {[]holidayRule{
+		holiday("Новий рік", fixedDay(1, 1)),
+		holiday("Різдво Христове", fixedDay(1, 7)).till(2023),
+		holiday("Міжнародний жіночий день", fixedDay(3, 8)),
+		holiday("Великдень", orthodoxDay(0)),
+		holiday("День праці", fixedDay(5, 1)),
+		holiday("День перемоги", fixedDay(5, 9)).till(2023),
+		holiday("День пам'яті та перемоги над нацизмом", fixedDay(5, 8)).since(2024),
+		holiday("Трійця", orthodoxDay(49)),
+		holiday("День Конституції", fixedDay(6, 28)),
+		holiday("День Української Державності", fixedDay(7, 28)).since(2022).till(2023),
+		holiday("День Української Державності", fixedDay(7, 15)).since(2024),
+		holiday("День Незалежності", fixedDay(8, 24)),
+		holiday("День захисників і захисниць", fixedDay(10, 14)).since(2015).till(2022),
+		holiday("День захисників і захисниць", fixedDay(10, 1)).since(2023),
+		holiday("Різдво Христове", fixedDay(12, 25)).since(2017),
+	}, "%s", "", ""},
+
+	"BG": {[]holidayRule{
+		holiday("Нова година", fixedDay(1, 1)).observed(nextWorkday),
+		holiday("Ден на Освобождението", fixedDay(3, 3)).observed(nextWorkday),
+		holiday("Велики петък", orthodoxDay(-2)),
+		holiday("Велика събота", orthodoxDay(-1)),
+		holiday("Великден", orthodoxDay(0)),
+		holiday("Великден", orthodoxDay(1)),
+		holiday("Ден на труда", fixedDay(5, 1)).observed(nextWorkday),
+		holiday("Гергьовден", fixedDay(5, 6)).observed(nextWorkday),
+		holiday("Ден на културата", fixedDay(5, 24)).observed(nextWorkday),
+		holiday("Ден на Съединението", fixedDay(9, 6)).observed(nextWorkday),
+		holiday("Ден на Независимостта", fixedDay(9, 22)).observed(nextWorkday),
+		holiday("Бъдни вечер", fixedDay(12, 24)).observed(nextWorkday),
+		holiday("Рождество Христово", fixedDay(12, 25)).observed(nextWorkday),
+		holiday("Рождество Христово", fixedDay(12, 26)).observed(nextWorkday),
+	}, "%s (почивен ден)", "", ""},
+
+	"CN": {[]holidayRule{
+		holiday("New Year's Day", fixedDay(1, 1)),
+		holiday("Spring Festival", lunarDay(1, 1, -1)).since(2025),
+		holiday("Spring Festival", lunarDay(1, 1, 0)),
+		holiday("Spring Festival", lunarDay(1, 1, 1)),
+		holiday("Spring Festival", lunarDay(1, 1, 2)),
+		holiday("Qingming Festival", qingming),
+		holiday("Labour Day", fixedDay(5, 1)),
+		holiday("Labour Day", fixedDay(5, 2)).since(2025),
+		holiday("Dragon Boat Festival", lunarDay(5, 5, 0)),
+		holiday("Mid-Autumn Festival", lunarDay(8, 15, 0)),
+		holiday("National Day", fixedDay(10, 1)),
+		holiday("National Day", fixedDay(10, 2)),
+		holiday("National Day", fixedDay(10, 3)),
+	}, "%s", "", ""},
+
+	"TW": {[]holidayRule{
+		holiday("Founding Day", fixedDay(1, 1)).observed(nearestWeekday),
+		holiday("Lunar New Year's Eve", lunarDay(1, 1, -1)),
+		holiday("Lunar New Year", lunarDay(1, 1, 0)),
+		holiday("Lunar New Year", lunarDay(1, 1, 1)),
+		holiday("Lunar New Year", lunarDay(1, 1, 2)),
+		holiday("Peace Memorial Day", fixedDay(2, 28)).observed(nearestWeekday),
+		holiday("Children's Day", fixedDay(4, 4)),
+		holiday("Tomb Sweeping Day", qingming),
+		holiday("Labour Day", fixedDay(5, 1)),
+		holiday("Dragon Boat Festival", lunarDay(5, 5, 0)).observed(nearestWeekday),
+		holiday("Mid-Autumn Festival", lunarDay(8, 15, 0)).observed(nearestWeekday),
+		holiday("National Day", fixedDay(10, 10)).observed(nearestWeekday),
+	}, "%s (observed)", "", ""},
+
+	"HK": {[]holidayRule{
+		holiday("The first day of January", fixedDay(1, 1)).observed(sundayNext),
+		holiday("Lunar New Year's Day", lunarDay(1, 1, 0)).observed(sundayNext),
+		holiday("The second day of Lunar New Year", lunarDay(1, 1, 1)).observed(sundayNext),
+		holiday("The third day of Lunar New Year", lunarDay(1, 1, 2)).observed(sundayNext),
+		holiday("Ching Ming Festival", qingming).observed(sundayNext),
+		holiday("Good Friday", easterDay(-2)),
+		holiday("The day following Good Friday", easterDay(-1)),
+		holiday("Easter Monday", easterDay(1)),
+		holiday("Labour Day", fixedDay(5, 1)).observed(sundayNext),
+		holiday("The Birthday of the Buddha", lunarDay(4, 8, 0)).observed(sundayNext),
+		holiday("Tuen Ng Festival", lunarDay(5, 5, 0)).observed(sundayNext),
+		holiday("HKSAR Establishment Day", fixedDay(7, 1)).observed(sundayNext),
+		holiday("The day following the Mid-Autumn Festival", lunarDay(8, 15, 1)).observed(sundayNext),
+		holiday("National Day", fixedDay(10, 1)).observed(sundayNext),
+		holiday("Chung Yeung Festival", lunarDay(9, 9, 0)).observed(sundayNext),
+		holiday("Christmas Day", fixedDay(12, 25)).observed(sundayNext),
+		holiday("The first weekday after Christmas Day", fixedDay(12, 26)).observed(sundayNext),
+	}, "%s (substitute)", "", ""},
+
+	"KR": {[]holidayRule{
+		holiday("New Year's Day", fixedDay(1, 1)),
+		holiday("Seollal", lunarDay(1, 1, -1)).observed(observedSince(2014, sundayNext)),
+		holiday("Seollal", lunarDay(1, 1, 0)).observed(observedSince(2014, sundayNext)),
+		holiday("Seollal", lunarDay(1, 1, 1)).observed(observedSince(2014, sundayNext)),
+		holiday("Independence Movement Day", fixedDay(3, 1)).observed(observedSince(2021, nextWorkday)),
+		holiday("Children's Day", fixedDay(5, 5)).observed(observedSince(2014, nextWorkday)),
+		holiday("Buddha's Birthday", lunarDay(4, 8, 0)).observed(observedSince(2023, nextWorkday)),
+		holiday("Memorial Day", fixedDay(6, 6)),
+		holiday("Liberation Day", fixedDay(8, 15)).observed(observedSince(2021, nextWorkday)),
+		holiday("Chuseok", lunarDay(8, 15, -1)).observed(observedSince(2014, sundayNext)),
+		holiday("Chuseok", lunarDay(8, 15, 0)).observed(observedSince(2014, sundayNext)),
+		holiday("Chuseok", lunarDay(8, 15, 1)).observed(observedSince(2014, sundayNext)),
+		holiday("National Foundation Day", fixedDay(10, 3)).observed(observedSince(2021, nextWorkday)),
+		holiday("Hangul Day", fixedDay(10, 9)).observed(observedSince(2021, nextWorkday)),
+		holiday("Christmas Day", fixedDay(12, 25)).observed(observedSince(2023, nextWorkday)),
+	}, "%s (substitute)", "", ""},
+
+	"JP": {[]holidayRule{
+		holiday("New Year's Day", fixedDay(1, 1)).observed(sundayNext),
+		holiday("Coming of Age Day", fixedDay(1, 15)).till(1999).observed(sundayNext),
+		holiday("Coming of Age Day", nthWeekday(2, time.Monday, 1)).since(2000),
+		holiday("National Foundation Day", fixedDay(2, 11)).observed(sundayNext),
+		holiday("Emperor's Birthday", fixedDay(2, 23)).since(2020).observed(sundayNext),
+		holiday("Vernal Equinox Day", equinoxJapan(3)).observed(sundayNext),
+		holiday("Greenery Day", fixedDay(4, 29)).till(2006).observed(sundayNext),
+		holiday("Shōwa Day", fixedDay(4, 29)).since(2007).observed(sundayNext),
+		holiday("Constitution Memorial Day", fixedDay(5, 3)).observed(sundayNext),
+		holiday("Greenery Day", fixedDay(5, 4)).since(2007).observed(sundayNext),
+		holiday("Children's Day", fixedDay(5, 5)).observed(sundayNext),
+		holiday("Marine Day", fixedDay(7, 20)).since(1996).till(2002).observed(sundayNext),
+		holiday("Marine Day", nthWeekday(3, time.Monday, 7)).since(2003),
+		holiday("Mountain Day", fixedDay(8, 11)).since(2016).observed(sundayNext),
+		holiday("Respect for the Aged Day", fixedDay(9, 15)).till(2002).observed(sundayNext),
+		holiday("Respect for the Aged Day", nthWeekday(3, time.Monday, 9)).since(2003),
+		holiday("Autumnal Equinox Day", equinoxJapan(9)).observed(sundayNext),
+		holiday("Sports Day", fixedDay(10, 10)).till(1999).observed(sundayNext),
+		holiday("Sports Day", nthWeekday(2, time.Monday, 10)).since(2000),
+		holiday("Culture Day", fixedDay(11, 3)).observed(sundayNext),
+		holiday("Labour Thanksgiving Day", fixedDay(11, 23)).observed(sundayNext),
+		holiday("Emperor's Birthday", fixedDay(12, 23)).since(1989).till(2018).observed(sundayNext),
+	}, "%s (substitute)", "", "Citizens' Holiday"},
+
+	"GR": {[]holidayRule{
+		holiday("Πρωτοχρονιά", fixedDay(1, 1)),
+		holiday("Θεοφάνεια", fixedDay(1, 6)),
+		holiday("Καθαρά Δευτέρα", orthodoxDay(-48)),
+		holiday("Ευαγγελισμός της Θεοτόκου", fixedDay(3, 25)),
+		holiday("Μεγάλη Παρασκευή", orthodoxDay(-2)),
+		holiday("Πάσχα", orthodoxDay(0)),
+		holiday("Δευτέρα του Πάσχα", orthodoxDay(1)),
+		holiday("Πρωτομαγιά", fixedDay(5, 1)),
+		holiday("Αγίου Πνεύματος", orthodoxDay(50)),
+		holiday("Κοίμηση της Θεοτόκου", fixedDay(8, 15)),
+		holiday("Επέτειος του Όχι", fixedDay(10, 28)),
+		holiday("Χριστούγεννα", fixedDay(12, 25)),
+		holiday("Σύναξη της Θεοτόκου", fixedDay(12, 26)),
+	}, "%s", "", ""},
+
+	"ID": {[]holidayRule{
+		holiday("Tahun Baru Masehi", fixedDay(1, 1)),
+		holiday("Isra Mikraj", hijriDay(7, 27)),
+		holiday("Tahun Baru Imlek", lunarDay(1, 1, 0)),
+		holiday("Wafat Yesus Kristus", easterDay(-2)),
+		holiday("Kebangkitan Yesus Kristus", easterDay(0)).since(2024),
+		holiday("Hari Raya Idul Fitri", hijriDay(10, 1)),
+		holiday("Hari Raya Idul Fitri", hijriDay(10, 2)),
+		holiday("Hari Buruh Internasional", fixedDay(5, 1)),
+		holiday("Kenaikan Yesus Kristus", easterDay(39)),
+		holiday("Hari Lahir Pancasila", fixedDay(6, 1)).since(2017),
+		holiday("Hari Raya Idul Adha", hijriDay(12, 10)),
+		holiday("Tahun Baru Islam", hijriDay(1, 1)),
+		holiday("Hari Kemerdekaan", fixedDay(8, 17)),
+		holiday("Maulid Nabi Muhammad", hijriDay(3, 12)),
+		holiday("Hari Raya Natal", fixedDay(12, 25)),
+	}, "%s", "", ""},
+
+	"CZ": {[]holidayRule{
+		holiday("Nový rok", fixedDay(1, 1)),
+		holiday("Velký pátek", easterDay(-2)).since(2016),
+		holiday("Velikonoční pondělí", easterDay(1)),
+		holiday("Svátek práce", fixedDay(5, 1)),
+		holiday("Den vítězství", fixedDay(5, 8)),
+		holiday("Den slovanských věrozvěstů Cyrila a Metoděje", fixedDay(7, 5)),
+		holiday("Den upálení mistra Jana Husa", fixedDay(7, 6)),
+		holiday("Den české státnosti", fixedDay(9, 28)),
+		holiday("Den vzniku samostatného československého státu", fixedDay(10, 28)),
+		holiday("Den boje za svobodu a demokracii", fixedDay(11, 17)),
+		holiday("Štědrý den", fixedDay(12, 24)),
+		holiday("1. svátek vánoční", fixedDay(12, 25)),
+		holiday("2. svátek vánoční", fixedDay(12, 26)),
+	}, "%s", "", ""},
+
+	"SI": {[]holidayRule{
+		holiday("Novo leto", fixedDay(1, 1)),
+		holiday("Novo leto", fixedDay(1, 2)),
+		holiday("Prešernov dan", fixedDay(2, 8)),
+		holiday("Velikonočna nedelja", easterDay(0)),
+		holiday("Velikonočni ponedeljek", easterDay(1)),
+		holiday("Dan upora proti okupatorju", fixedDay(4, 27)),
+		holiday("Praznik dela", fixedDay(5, 1)),
+		holiday("Praznik dela", fixedDay(5, 2)),
+		holiday("Binkoštna nedelja", easterDay(49)),
+		holiday("Dan državnosti", fixedDay(6, 25)),
+		holiday("Marijino vnebovzetje", fixedDay(8, 15)),
+		holiday("Dan reformacije", fixedDay(10, 31)),
+		holiday("Dan spomina na mrtve", fixedDay(11, 1)),
+		holiday("Božič", fixedDay(12, 25)),
+		holiday("Dan samostojnosti in enotnosti", fixedDay(12, 26)),
+	}, "%s", "", ""},
+
+	"LT": {[]holidayRule{
+		holiday("Naujieji metai", fixedDay(1, 1)),
+		holiday("Lietuvos valstybės atkūrimo diena", fixedDay(2, 16)),
+		holiday("Lietuvos nepriklausomybės atkūrimo diena", fixedDay(3, 11)),
+		holiday("Velykos", easterDay(0)),
+		holiday("Antroji Velykų diena", easterDay(1)),
+		holiday("Tarptautinė darbo diena", fixedDay(5, 1)),
+		holiday("Motinos diena", nthWeekday(1, time.Sunday, 5)),
+		holiday("Tėvo diena", nthWeekday(1, time.Sunday, 6)),
+		holiday("Joninės", fixedDay(6, 24)),
+		holiday("Valstybės diena", fixedDay(7, 6)),
+		holiday("Žolinė", fixedDay(8, 15)),
+		holiday("Visų šventųjų diena", fixedDay(11, 1)),
+		holiday("Vėlinės", fixedDay(11, 2)).since(2020),
+		holiday("Kūčios", fixedDay(12, 24)),
+		holiday("Kalėdos", fixedDay(12, 25)),
+		holiday("Antroji Kalėdų diena", fixedDay(12, 26)),
+	}, "%s", "", ""},
+
+	"TH": {[]holidayRule{
+		holiday("New Year's Day", fixedDay(1, 1)).observed(nextWorkday),
+		holiday("Chakri Memorial Day", fixedDay(4, 6)).observed(nextWorkday),
+		holiday("Songkran Festival", fixedDay(4, 13)).observed(nextWorkday),
+		holiday("Songkran Festival", fixedDay(4, 14)).observed(nextWorkday),
+		holiday("Songkran Festival", fixedDay(4, 15)).observed(nextWorkday),
+		holiday("National Labour Day", fixedDay(5, 1)).observed(nextWorkday),
+		holiday("Coronation Day", fixedDay(5, 4)).since(2020).observed(nextWorkday),
+		holiday("Queen Suthida's Birthday", fixedDay(6, 3)).since(2019).observed(nextWorkday),
+		holiday("King Vajiralongkorn's Birthday", fixedDay(7, 28)).since(2017).observed(nextWorkday),
+		holiday("Mother's Day", fixedDay(8, 12)).observed(nextWorkday),
+		holiday("King Bhumibol Memorial Day", fixedDay(10, 13)).since(2017).observed(nextWorkday),
+		holiday("Chulalongkorn Day", fixedDay(10, 23)).observed(nextWorkday),
+		holiday("Father's Day", fixedDay(12, 5)).observed(nextWorkday),
+		holiday("Constitution Day", fixedDay(12, 10)).observed(nextWorkday),
+		holiday("New Year's Eve", fixedDay(12, 31)).observed(nextWorkday),
+	}, "%s (substitute)", "", ""},
+
+	"UZ": {[]holidayRule{
+		holiday("Yangi yil", fixedDay(1, 1)),
+		holiday("Xotin-qizlar kuni", fixedDay(3, 8)),
+		holiday("Navro'z bayrami", fixedDay(3, 21)),
+		holiday("Xotira va qadrlash kuni", fixedDay(5, 9)),
+		holiday("Mustaqillik kuni", fixedDay(9, 1)),
+		holiday("O'qituvchi va murabbiylar kuni", fixedDay(10, 1)),
+		holiday("Konstitutsiya kuni", fixedDay(12, 8)),
+		holiday("Ramazon hayit", hijriDay(10, 1)),
+		holiday("Qurbon hayit", hijriDay(12, 10)),
+	}, "%s", "", ""},
+}
+
+// holidayEvents returns the holidays of the calendar from the day
+// from to the day to as events and as a set of days in the format
+// YYYY-MM-DD.
+func (g *Calendar) holidayEvents(from, to time.Time) ([]Event, map[string]bool, error) {
+	days := make(map[string]bool)
+	if g.OptHolidays == "" {
+		return nil, days, nil
+	}
+	hs, err := holidays(g.OptHolidays, from, to)
+	if err != nil {
+		return nil, nil, err
+	}
+	var events []Event
+	for _, hd := range hs {
+		events = append(events, Event{Month: hd.date.Month(), Day: hd.date.Day(), Text: hd.name, Year: hd.date.Year(), Days: 1, Category: "holiday"})
+		days[hd.date.Format("2006-01-02")] = true
+	}
+	return events, days, nil
+}
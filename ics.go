@@ -0,0 +1,442 @@
+package gocal
+
+// Copyright (c) 2014 Stefan Schroeder, NY, 2014-03-10
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file
+//
+// ics.go
+//
+// This file is part of gocal, a PDF calendar generator in Go.
+//
+// https://github.com/StefanSchroeder/Gocal
+//
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// icsProperty is a content line of an ICS file, e.g.
+// DTSTART;TZID=Europe/Berlin:20260105T093000.
+type icsProperty struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// icsTime is a date or a moment of an ICS file.
+type icsTime struct {
+	t      time.Time
+	allDay bool // a date without time, t is midnight UTC
+}
+
+// icsEvent is a VEVENT of an ICS file.
+type icsEvent struct {
+	uid          string
+	summary      string
+	status       string
+	categories   []string
+	start        icsTime
+	end          *icsTime
+	duration     *time.Duration
+	rrule        string
+	rdates       []icsTime
+	exdates      []icsTime
+	recurrenceID *icsTime
+}
+
+// icsData is an ICS calendar given to AddICSReader.
+type icsData struct {
+	data []byte
+	err  error // error of the reader
+}
+
+// icsLine is a content line and the number of its first line
+// in the file.
+type icsLine struct {
+	n    int
+	text string
+}
+
+// unfoldICS reads the content lines of r. A line that begins with
+// a space or a tab continues the previous line.
+func unfoldICS(r io.Reader) (lines []icsLine, err error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	n := 0
+	for sc.Scan() {
+		n++
+		l := strings.TrimRight(sc.Text(), "\r")
+		if n == 1 {
+			l = strings.TrimPrefix(l, "\uFEFF") // BOM of Outlook
+		}
+		if (strings.HasPrefix(l, " ") || strings.HasPrefix(l, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1].text += l[1:]
+			continue
+		}
+		if strings.TrimSpace(l) != "" {
+			lines = append(lines, icsLine{n, l})
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("line %d: %v", n+1, err)
+	}
+	return lines, nil
+}
+
+// parseICSLine splits a content line into the name, the parameters
+// and the value. Parameter values may be quoted.
+func parseICSLine(line string) (p icsProperty, err error) {
+	p.params = map[string]string{}
+	quoted := false
+	colon := -1
+	for i, c := range line {
+		if c == '"' {
+			quoted = !quoted
+		} else if c == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return p, fmt.Errorf("line without value %q", line)
+	}
+	p.value = line[colon+1:]
+	parts := strings.Split(line[:colon], ";")
+	p.name = strings.ToUpper(parts[0])
+	for _, param := range parts[1:] {
+		kv := strings.SplitN(param, "=", 2)
+		if len(kv) == 2 {
+			p.params[strings.ToUpper(kv[0])] = strings.Trim(kv[1], `"`)
+		}
+	}
+	return p, nil
+}
+
+// icsText unescapes a text value.
+func icsText(s string) string {
+	r := strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`)
+	return r.Replace(s)
+}
+
+// parseICSTimes parses the dates or moments of the property p, which
+// may be a comma separated list. Moments without Z are in the time
+// zone zone.
+func parseICSTimes(p icsProperty, zone *time.Location) (out []icsTime, err error) {
+	for _, v := range strings.Split(p.value, ",") {
+		if i := strings.Index(v, "/"); i >= 0 { // PERIOD, the start counts
+			v = v[:i]
+		}
+		var t time.Time
+		switch {
+		case len(v) == 8:
+			t, err = time.Parse("20060102", v)
+			out = append(out, icsTime{t, true})
+		case strings.HasSuffix(v, "Z"):
+			t, err = time.Parse("20060102T150405Z", v)
+			out = append(out, icsTime{t, false})
+		default:
+			t, err = time.ParseInLocation("20060102T150405", v, zone)
+			out = append(out, icsTime{t, false})
+		}
+		if err != nil {
+			return nil, fmt.Errorf("bad time %q", v)
+		}
+	}
+	return out, nil
+}
+
+// parseICSDuration parses a duration like P1D, PT1H30M or P2W.
+func parseICSDuration(s string) (d time.Duration, err error) {
+	sign := time.Duration(1)
+	if strings.HasPrefix(s, "-") {
+		sign = -1
+	}
+	s = strings.TrimLeft(s, "+-")
+	if !strings.HasPrefix(s, "P") {
+		return 0, fmt.Errorf("bad duration %q", s)
+	}
+	units := map[byte]time.Duration{'W': 7 * 24 * time.Hour, 'D': 24 * time.Hour, 'H': time.Hour, 'M': time.Minute, 'S': time.Second}
+	n := ""
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == 'T':
+		case c >= '0' && c <= '9':
+			n += string(c)
+		default:
+			u, ok := units[c]
+			v, err := strconv.Atoi(n)
+			if !ok || err != nil {
+				return 0, fmt.Errorf("bad duration %q", s)
+			}
+			d += time.Duration(v) * u
+			n = ""
+		}
+	}
+	return sign * d, nil
+}
+
+// icsParser collects the events of an ICS file line by line.
+type icsParser struct {
+	loc    *time.Location // time zone of floating times
+	ev     *icsEvent      // the VEVENT being read
+	depth  int            // components inside the VEVENT, e.g. VALARM
+	name   string         // X-WR-CALNAME of the calendar
+	events []icsEvent
+	zones  map[string]*time.Location // by TZID
+	logf   func(format string, v ...interface{})
+}
+
+// parseICS returns the events and the name of the calendar of the
+// ICS data in r. Floating times are in the time zone loc. Malformed
+// data is reported with the number of the line, unknown time zones
+// to logf.
+func parseICS(r io.Reader, loc *time.Location, logf func(format string, v ...interface{})) (events []icsEvent, name string, err error) {
+	lines, err := unfoldICS(r)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(lines) == 0 || strings.ToUpper(lines[0].text) != "BEGIN:VCALENDAR" {
+		return nil, "", fmt.Errorf("not an iCalendar file")
+	}
+	p := icsParser{loc: loc, zones: map[string]*time.Location{}, logf: logf}
+	begin := 0
+	for _, line := range lines {
+		if p.ev == nil {
+			begin = line.n
+		}
+		if err := p.line(line.text); err != nil {
+			return nil, "", fmt.Errorf("line %d: %v", line.n, err)
+		}
+	}
+	if p.ev != nil {
+		return nil, "", fmt.Errorf("line %d: VEVENT without END", begin)
+	}
+	return p.events, p.name, nil
+}
+
+// zone returns the time zone of the TZID tzid. The Windows names of
+// Outlook and Exchange, e.g. W. Europe Standard Time, are mapped to
+// IANA names. Other unknown zones are taken as the time zone of the
+// calendar, with a warning.
+func (p *icsParser) zone(tzid string) *time.Location {
+	if z, ok := p.zones[tzid]; ok {
+		return z
+	}
+	name := strings.TrimPrefix(tzid, "/")
+	if iana, ok := windowsZones[name]; ok {
+		name = iana
+	}
+	z, err := time.LoadLocation(name)
+	if err != nil {
+		p.logf("unknown time zone %q, using %s", tzid, p.loc)
+		z = p.loc
+	}
+	p.zones[tzid] = z
+	return z
+}
+
+// line reads one content line.
+func (p *icsParser) line(text string) error {
+	prop, err := parseICSLine(text)
+	if err != nil {
+		return err
+	}
+	isEvent := strings.ToUpper(prop.value) == "VEVENT"
+	switch {
+	case prop.name == "BEGIN" && isEvent:
+		if p.ev != nil {
+			return fmt.Errorf("VEVENT inside VEVENT")
+		}
+		p.ev = &icsEvent{}
+		return nil
+	case prop.name == "END" && isEvent && p.depth == 0:
+		if p.ev == nil {
+			return fmt.Errorf("END:VEVENT without BEGIN")
+		}
+		if p.ev.start.t.IsZero() {
+			return fmt.Errorf("event %q without DTSTART", p.ev.summary)
+		}
+		p.events = append(p.events, *p.ev)
+		p.ev = nil
+		return nil
+	case p.ev == nil:
+		if prop.name == "X-WR-CALNAME" {
+			p.name = icsText(prop.value)
+		}
+		return nil
+	case prop.name == "BEGIN":
+		p.depth++
+		return nil
+	case prop.name == "END" && p.depth > 0:
+		p.depth--
+		return nil
+	case p.depth > 0:
+		return nil
+	}
+
+	ev := p.ev
+	var times []icsTime
+	switch prop.name {
+	case "DTSTART", "DTEND", "RDATE", "EXDATE", "RECURRENCE-ID":
+		zone := p.loc
+		if tzid := prop.params["TZID"]; tzid != "" {
+			zone = p.zone(tzid)
+		}
+		if times, err = parseICSTimes(prop, zone); err != nil {
+			return err
+		}
+	}
+	switch prop.name {
+	case "UID":
+		ev.uid = prop.value
+	case "SUMMARY":
+		ev.summary = icsText(prop.value)
+	case "STATUS":
+		ev.status = strings.ToUpper(prop.value)
+	case "CATEGORIES":
+		ev.categories = append(ev.categories, splitTags(icsText(prop.value))...)
+	case "DTSTART":
+		ev.start = times[0]
+	case "DTEND":
+		ev.end = &times[0]
+	case "DURATION":
+		d, err := parseICSDuration(prop.value)
+		if err != nil {
+			return err
+		}
+		ev.duration = &d
+	case "RRULE":
+		ev.rrule = prop.value
+	case "RDATE":
+		ev.rdates = append(ev.rdates, times...)
+	case "EXDATE":
+		ev.exdates = append(ev.exdates, times...)
+	case "RECURRENCE-ID":
+		ev.recurrenceID = &times[0]
+	}
+	return nil
+}
+
+// key identifies an occurrence for EXDATE and RECURRENCE-ID.
+func (t icsTime) key() string {
+	if t.allDay {
+		return t.t.Format("2006-01-02")
+	}
+	return t.t.UTC().Format(time.RFC3339)
+}
+
+// length returns the duration of the event. An all-day event
+// without an end lasts one day.
+func (ev icsEvent) length() time.Duration {
+	switch {
+	case ev.end != nil:
+		return ev.end.t.Sub(ev.start.t)
+	case ev.duration != nil:
+		return *ev.duration
+	case ev.start.allDay:
+		return 24 * time.Hour
+	}
+	return 0
+}
+
+// icsEvents expands the events to the occurrences that overlap the
+// days from from to before to. Moments are shown in the time zone
+// loc and kept as the start and end of the event.
+func (g *Calendar) icsEvents(events []icsEvent, from, to time.Time, loc *time.Location) (eL []Event) {
+	// Occurrences that were changed are replaced by their own VEVENT.
+	changed := map[string]bool{}
+	for _, ev := range events {
+		if ev.recurrenceID != nil {
+			changed[ev.uid+" "+ev.recurrenceID.key()] = true
+		}
+	}
+
+	for _, ev := range events {
+		if ev.status == "CANCELLED" {
+			continue
+		}
+		length := ev.length()
+		starts := []icsTime{ev.start}
+		if ev.rrule != "" && ev.recurrenceID == nil {
+			rule, err := parseRecurrence(ev.rrule)
+			if err != nil {
+				g.logf("%s: only the first occurrence of '%s': %v", ev.summary, ev.rrule, err)
+			} else {
+				// A day more on each side for the conversion of time zones.
+				for _, t := range rule.between(ev.start.t, from.Add(-length).AddDate(0, 0, -1), to.AddDate(0, 0, 1)) {
+					starts = append(starts, icsTime{t, ev.start.allDay})
+				}
+			}
+		}
+		starts = append(starts, ev.rdates...)
+
+		skip := map[string]bool{}
+		for _, ex := range ev.exdates {
+			skip[ex.key()] = true
+		}
+		for _, s := range starts {
+			if skip[s.key()] || (ev.recurrenceID == nil && changed[ev.uid+" "+s.key()]) {
+				continue
+			}
+			skip[s.key()] = true // RDATE or DTSTART may repeat a rule date
+
+			first, end := s.t, s.t.Add(length)
+			if !s.allDay {
+				first, end = first.In(loc), end.In(loc)
+			}
+			// The end is exclusive.
+			last := first
+			if end.After(first) {
+				last = end.Add(-time.Nanosecond)
+			}
+			e := spanEvent(first, last, ev.summary, "")
+			if !s.allDay {
+				e.Start, e.End = first, end
+			}
+			// The first category gives the style, all are tags.
+			if len(ev.categories) > 0 {
+				e.Category = ev.categories[0]
+			}
+			e.Tags = ev.categories
+			// Birthdays and anniversaries count from the first one.
+			e.Origin = ev.start.t.Year()
+			firstDay := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, time.UTC)
+			lastDay := firstDay.AddDate(0, 0, e.Days-1)
+			if firstDay.Before(to) && !lastDay.Before(from) {
+				eL = append(eL, e)
+			}
+		}
+	}
+	return eL
+}
+
+// This function reads the ICS file and returns the events that
+// overlap the days between from and to, in the time zone loc.
+func (g *Calendar) readICSfile(filename string, from, to time.Time, loc *time.Location) (eL []Event, err error) {
+	f, err := g.openICS(filename)
+	if err != nil {
+		return nil, &Error{ErrICS, filename, err}
+	}
+	defer f.Close()
+	return g.readICS(filename, f, from, to, loc)
+}
+
+// readICS is readICSfile for the ICS data in r. The calendar is
+// left out if its file name or X-WR-CALNAME is an excluded source.
+func (g *Calendar) readICS(name string, r io.Reader, from, to time.Time, loc *time.Location) (eL []Event, err error) {
+	events, calName, err := parseICS(r, loc, g.logf)
+	if err != nil {
+		return nil, &Error{ErrICS, name, err}
+	}
+	if g.excludedSource(name, calName) {
+		return nil, nil
+	}
+	return g.icsEvents(events, from, to, loc), nil
+}
@@ -0,0 +1,191 @@
+package gocal
+
+// Copyright (c) 2014 Stefan Schroeder, NY, 2014-03-10
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file
+//
+// layout.go
+//
+// This file is part of gocal, a PDF calendar generator in Go.
+//
+// https://github.com/StefanSchroeder/Gocal
+//
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// MINEVENTSCALE is the smallest size of the event font when it
+// shrinks to fit, relative to EVENTFONTSIZE.
+const MINEVENTSCALE = 0.6
+
+// wrapText splits the text at the \n and breaks lines that are wider
+// than w at spaces, and words that are wider than w anywhere.
+func wrapText(pdf *gofpdf.Fpdf, text string, w float64) (lines []string) {
+	for _, para := range strings.Split(text, "\\n") {
+		line := ""
+		for _, word := range strings.Fields(para) {
+			try := word
+			if line != "" {
+				try = line + " " + word
+			}
+			if pdf.GetStringWidth(try) <= w {
+				line = try
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+			// A word that is too long on its own line is broken.
+			r := []rune(word)
+			for len(r) > 1 && pdf.GetStringWidth(string(r)) > w {
+				n := len(r) - 1
+				for n > 1 && pdf.GetStringWidth(string(r[:n])) > w {
+					n--
+				}
+				lines = append(lines, string(r[:n]))
+				r = r[n:]
+			}
+			line = string(r)
+		}
+		// Empty lines, e.g. of a leading \n that used to keep events
+		// apart, are left out; the events are stacked anyway.
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// cellEvent is an event in the list of a day cell and its lines.
+type cellEvent struct {
+	ev    Event
+	lines []string
+}
+
+// fitEvents lays out the events of a day one below the other in a
+// box of width w, where the baseline of the last line can be h below
+// the first. Events that do not fit are hidden and counted in a line
+// "+N more". The last event shows the lines that fit above that line,
+// but is counted as hidden if it is cut. With shrink the font gets
+// smaller, down to MINEVENTSCALE of size, before events are hidden.
+// The dot of a category indents the text.
+func fitEvents(pdf *gofpdf.Fpdf, events []Event, styles map[string]style, w, h, size float64, shrink bool) (shown []cellEvent, hidden []Event, fontSize float64) {
+	fontSize = size
+	for {
+		pdf.SetFontSize(fontSize)
+		line := fontSize / 3.0
+		room := int(h/line) + 1
+		smallest := !shrink || fontSize*0.9 < size*MINEVENTSCALE
+		shown, hidden = nil, nil
+		used := 0
+		for i, ev := range events {
+			tw := w
+			if styles[strings.ToLower(ev.Category)].dot != nil {
+				tw -= line / 2
+			}
+			lines := wrapText(pdf, ev.Text, tw)
+			free := room - used
+			if i < len(events)-1 {
+				free-- // for the line "+N more"
+			}
+			if len(hidden) > 0 || len(lines) > free {
+				// The last event keeps a line for "+N more".
+				if i == len(events)-1 && len(hidden) == 0 && free > 1 {
+					shown = append(shown, cellEvent{ev, lines[:free-1]})
+					used += free - 1
+				}
+				hidden = append(hidden, ev)
+				continue
+			}
+			shown = append(shown, cellEvent{ev, lines})
+			used += len(lines)
+		}
+		if len(hidden) == 0 || smallest {
+			return shown, hidden, fontSize
+		}
+		fontSize *= 0.9
+	}
+}
+
+// moreTexts are the lines for hidden events by language.
+var moreTexts = map[string]string{
+	"en": "+%d more",
+	"de": "+%d weitere",
+	"fr": "+%d autres",
+	"es": "+%d más",
+	"it": "+%d altri",
+	"pt": "+%d mais",
+	"nl": "+%d meer",
+	"da": "+%d flere",
+	"nb": "+%d flere",
+	"sv": "+%d till",
+	"fi": "+%d muuta",
+	"pl": "+%d więcej",
+	"cs": "+%d další",
+	"ru": "ещё %d",
+	"uk": "ще %d",
+	"el": "+%d ακόμη",
+	"tr": "+%d daha",
+	"he": "+%d נוספים",
+	"ar": "+%d أخرى",
+	"fa": "+%d مورد دیگر",
+	"ja": "他%d件",
+	"zh": "另外%d项",
+}
+
+// moreText is the line for n hidden events in the language of the
+// locale, e.g. de_DE, or in English.
+func moreText(locale string, n int) string {
+	f, ok := moreTexts[strings.SplitN(locale, "_", 2)[0]]
+	if !ok {
+		f = moreTexts["en"]
+	}
+	return fmt.Sprintf(f, n)
+}
+
+// agendaItem is an event that did not fit into its day cell.
+type agendaItem struct {
+	day  time.Time
+	text string
+}
+
+// drawAgenda lists the items under each other from the current
+// position, the day in a column of its own, in the width w.
+func drawAgenda(pdf *gofpdf.Fpdf, items []agendaItem, weekdayNames []string, w, fontSize float64, rtl bool) {
+	pdf.SetFontSize(fontSize)
+	pdf.SetTextColor(BLACK, BLACK, BLACK)
+	line := fontSize / 2.5
+	left := pdf.GetX()
+	dw := pdf.GetStringWidth("Www 00") + 2*CELLMARGIN
+	for _, name := range weekdayNames {
+		if d := pdf.GetStringWidth(visual(name)+" 00") + 2*CELLMARGIN; d > dw {
+			dw = d
+		}
+	}
+	var last time.Time
+	for _, it := range items {
+		day := ""
+		if !it.day.Equal(last) {
+			day = visual(fmt.Sprintf("%s %d", weekdayNames[(it.day.Weekday()+1)%7], it.day.Day()))
+			last = it.day
+		}
+		text := visual(strings.Replace(it.text, "\\n", " ", -1))
+		y := pdf.GetY()
+		if rtl {
+			pdf.SetXY(left+w-dw, y)
+			pdf.CellFormat(dw, line, day, "", 0, "R", false, 0, "")
+			pdf.SetXY(left, y)
+			pdf.MultiCell(w-dw, line, text, "", "R", false)
+		} else {
+			pdf.SetXY(left, y)
+			pdf.CellFormat(dw, line, day, "", 0, "L", false, 0, "")
+			pdf.MultiCell(w-dw, line, text, "", "L", false)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package gocal
+
+// Copyright (c) 2014 Stefan Schroeder, NY, 2014-03-10
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file
+//
+// moon.go
+//
+// This file is part of gocal, a PDF calendar generator in Go.
+//
+// https://github.com/StefanSchroeder/Gocal
+//
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/soniakeys/meeus/v3/moonillum"
+	"github.com/soniakeys/meeus/v3/moonphase"
+)
+
+// lunationsPerYear is the mean number of lunations in a year.
+const lunationsPerYear = 12.3685
+
+// moonQuarters are the principal phases of a lunation, at the
+// fraction q of it.
+var moonQuarters = []struct {
+	name  string
+	q     float64
+	phase func(float64) float64
+}{
+	{"New", 0, moonphase.New},
+	{"First", 0.25, moonphase.First},
+	{"Full", 0.5, moonphase.Full},
+	{"Last", 0.75, moonphase.Last},
+}
+
+// moonPhaseNames are the names of the phases New, First, Full and
+// Last by language.
+var moonPhaseNames = map[string][4]string{
+	"en": {"New moon", "First quarter", "Full moon", "Last quarter"},
+	"de": {"Neumond", "Erstes Viertel", "Vollmond", "Letztes Viertel"},
+	"fr": {"Nouvelle lune", "Premier quartier", "Pleine lune", "Dernier quartier"},
+	"es": {"Luna nueva", "Cuarto creciente", "Luna llena", "Cuarto menguante"},
+	"it": {"Luna nuova", "Primo quarto", "Luna piena", "Ultimo quarto"},
+	"pt": {"Lua nova", "Quarto crescente", "Lua cheia", "Quarto minguante"},
+	"nl": {"Nieuwe maan", "Eerste kwartier", "Volle maan", "Laatste kwartier"},
+	"da": {"Nymåne", "Første kvarter", "Fuldmåne", "Sidste kvarter"},
+	"nb": {"Nymåne", "Første kvarter", "Fullmåne", "Siste kvarter"},
+	"sv": {"Nymåne", "Första kvarteret", "Fullmåne", "Sista kvarteret"},
+	"fi": {"Uusikuu", "Kasvava puolikuu", "Täysikuu", "Vähenevä puolikuu"},
+	"pl": {"Nów", "Pierwsza kwadra", "Pełnia", "Ostatnia kwadra"},
+	"cs": {"Nov", "První čtvrť", "Úplněk", "Poslední čtvrť"},
+	"ru": {"Новолуние", "Первая четверть", "Полнолуние", "Последняя четверть"},
+	"uk": {"Молодик", "Перша чверть", "Повня", "Остання чверть"},
+	"el": {"Νέα Σελήνη", "Πρώτο τέταρτο", "Πανσέληνος", "Τελευταίο τέταρτο"},
+	"tr": {"Yeni ay", "İlk dördün", "Dolunay", "Son dördün"},
+	"he": {"מולד", "רבע ראשון", "ירח מלא", "רבע אחרון"},
+	"ar": {"محاق", "تربيع أول", "بدر", "تربيع أخير"},
+	"fa": {"ماه نو", "تربیع اول", "ماه کامل", "تربیع آخر"},
+	"ja": {"新月", "上弦", "満月", "下弦"},
+	"zh": {"新月", "上弦月", "满月", "下弦月"},
+}
+
+// moonName returns the name of the phase New, First, Full or Last
+// in the language of the locale, e.g. de_DE, or in English.
+func moonName(locale, phase string) string {
+	names, ok := moonPhaseNames[strings.SplitN(locale, "_", 2)[0]]
+	if !ok {
+		names = moonPhaseNames["en"]
+	}
+	for i, mq := range moonQuarters {
+		if mq.name == phase {
+			return names[i]
+		}
+	}
+	return phase
+}
+
+// moonPhase is a principal phase of the moon: New, First, Full
+// or Last, at the moment t.
+type moonPhase struct {
+	name string
+	t    time.Time
+}
+
+// jdeTime returns the moment of the Julian ephemeris day jde.
+func jdeTime(jde float64) time.Time {
+	jd := jde - deltaT(jde)
+	sec := (jd - 2440587.5) * 86400 // 2440587.5 is 1970-01-01 00:00 UTC
+	return time.Unix(0, 0).UTC().Add(time.Duration(math.Round(sec)) * time.Second)
+}
+
+// moonPhases returns the principal phases of the moon from the
+// moment from to before the moment to, in the time zone loc. The
+// phases are computed once for each lunation.
+func moonPhases(from, to time.Time, loc *time.Location) (phases []moonPhase) {
+	y := float64(from.Year()) + float64(from.YearDay()-1)/365.25
+	k := math.Floor((y-2000)*lunationsPerYear) - 1 // the lunation before
+	for ; ; k++ {
+		for _, mq := range moonQuarters {
+			t := jdeTime(mq.phase(2000 + (k+mq.q)/lunationsPerYear))
+			if !t.Before(to) {
+				return phases
+			}
+			if !t.Before(from) {
+				phases = append(phases, moonPhase{mq.name, t.In(loc)})
+			}
+		}
+	}
+}
+
+// moonModel holds the moon phases of a calendar and how they are
+// drawn. The month and the year views share it.
+type moonModel struct {
+	phases map[string]moonPhase // by the day in the format 2006-01-02
+	loc    *time.Location
+	mode   string // "" for the symbols, "quarters" or "daily"
+	south  bool
+}
+
+// moons returns the moon phases of the days from from to before to.
+// The days are those of the time zone of SetMoonLocation or
+// SetTimezone, or of UTC, so that they do not depend on the time
+// zone of the computer.
+func (g *Calendar) moons(from, to time.Time) (*moonModel, error) {
+	loc := g.OptMoonLocation
+	if loc == nil && g.OptTimezone == "" {
+		loc = time.UTC
+	}
+	if loc == nil {
+		var err error
+		if loc, err = g.location(); err != nil {
+			return nil, err
+		}
+	}
+	m := &moonModel{map[string]moonPhase{}, loc, g.OptMoonMode, g.OptSouthern}
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc)
+	to = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, loc)
+	for _, p := range moonPhases(from, to, loc) {
+		m.phases[p.t.Format("2006-01-02")] = p
+	}
+	return m, nil
+}
+
+// moonLit returns the lit fraction of the moon at the moment t and
+// whether it is waxing.
+func moonLit(t time.Time) (k float64, waxing bool) {
+	jd := float64(t.Unix())/86400 + 2440587.5
+	jde := jd + deltaT(jd)
+	// The phase angle is positive from the new to the full moon.
+	i := math.Remainder(float64(moonillum.PhaseAngle3(jde)), 2*math.Pi)
+	return (1 + math.Cos(i)) / 2, i > 0
+}
+
+// draw draws the moon of the day t at x, y with the radius r: the
+// symbol of the phase on the days of the phases, or in the modes
+// quarters and daily the lit part at noon. It tells if a moon was
+// drawn.
+func (m *moonModel) draw(pdf *gofpdf.Fpdf, t time.Time, x, y, r float64) bool {
+	p, ok := m.phases[t.Format("2006-01-02")]
+	mp := myPdf{pdf, r, m.south}
+	switch {
+	case m.mode == "daily" || (ok && m.mode == "quarters"):
+		k, waxing := moonLit(time.Date(t.Year(), t.Month(), t.Day(), 12, 0, 0, 0, m.loc))
+		mp.litMoon(k, waxing, x, y)
+	case ok:
+		mp.moon(p, x, y)
+	default:
+		return false
+	}
+	return true
+}
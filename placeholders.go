@@ -0,0 +1,53 @@
+package gocal
+
+// Copyright (c) 2014 Stefan Schroeder, NY, 2014-03-10
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file
+//
+// placeholders.go
+//
+// This file is part of gocal, a PDF calendar generator in Go.
+//
+// https://github.com/StefanSchroeder/Gocal
+//
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// expand replaces the placeholders in the text of the event ev on
+// the day t:
+//
+//	{age}, {years}  years since the origin year, e.g. 36
+//	{name}          the name of the event
+//	{doy}           day of the year, 1 to 366
+//	{daysleft}      days until the end of the year
+//	{weekday}       the name of the weekday, given as weekday
+//
+// {age} and {years} stay in the text if the event has no origin
+// year. Before the origin year they are left out, with the
+// parentheses around them. Other text in braces is not changed.
+func (ev Event) expand(t time.Time, weekday string) string {
+	if !strings.Contains(ev.Text, "{") {
+		return ev.Text
+	}
+	yearDays := time.Date(t.Year(), 12, 31, 0, 0, 0, 0, time.UTC).YearDay()
+	pairs := []string{
+		"{name}", ev.Name,
+		"{doy}", strconv.Itoa(t.YearDay()),
+		"{daysleft}", strconv.Itoa(yearDays - t.YearDay()),
+		"{weekday}", weekday,
+	}
+	switch {
+	case ev.Origin == 0:
+	case t.Year() < ev.Origin:
+		pairs = append(pairs, " ({age})", "", " ({years})", "", "{age}", "", "{years}", "")
+	default:
+		age := strconv.Itoa(t.Year() - ev.Origin)
+		pairs = append(pairs, "{age}", age, "{years}", age)
+	}
+	return strings.NewReplacer(pairs...).Replace(ev.Text)
+}
@@ -0,0 +1,392 @@
+package gocal
+
+// Copyright (c) 2014 Stefan Schroeder, NY, 2014-03-10
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file
+//
+// recur.go
+//
+// This file is part of gocal, a PDF calendar generator in Go.
+//
+// https://github.com/StefanSchroeder/Gocal
+//
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// recurrence is a rule for repeating events in the syntax of the
+// RRULE of RFC 5545, e.g. FREQ=MONTHLY;BYDAY=-1FR for the last
+// Friday of every month. The parts BYSECOND, BYMINUTE, BYHOUR,
+// BYWEEKNO and BYYEARDAY are not supported.
+type recurrence struct {
+	freq       string
+	interval   int
+	count      int
+	until      time.Time // zero if there is no end
+	untilDate  bool      // until is a date without a time
+	byMonth    []int
+	byMonthDay []int
+	byDay      []weekdayNum
+	bySetPos   []int
+	wkst       time.Weekday
+}
+
+// weekdayNum is an entry of BYDAY, e.g. -1FR. n is 0 for every
+// matching weekday of the period.
+type weekdayNum struct {
+	n  int
+	wd time.Weekday
+}
+
+var rruleWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// parseRecurrence parses the rule s. A leading RRULE: is ignored.
+func parseRecurrence(s string) (r recurrence, err error) {
+	r.interval = 1
+	r.wkst = time.Monday
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	for _, part := range strings.Split(s, ";") {
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return r, fmt.Errorf("bad rule part %q", part)
+		}
+		key, value := strings.ToUpper(kv[0]), strings.ToUpper(kv[1])
+		switch key {
+		case "FREQ":
+			switch value {
+			case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+				r.freq = value
+			default:
+				return r, fmt.Errorf("unsupported frequency %q", value)
+			}
+		case "INTERVAL":
+			if r.interval, err = strconv.Atoi(value); err != nil || r.interval < 1 {
+				return r, fmt.Errorf("bad interval %q", value)
+			}
+		case "COUNT":
+			if r.count, err = strconv.Atoi(value); err != nil || r.count < 1 {
+				return r, fmt.Errorf("bad count %q", value)
+			}
+		case "UNTIL":
+			if r.until, r.untilDate, err = parseRuleTime(value); err != nil {
+				return r, err
+			}
+		case "BYMONTH":
+			if r.byMonth, err = parseInts(value, 1, 12); err != nil {
+				return r, err
+			}
+		case "BYMONTHDAY":
+			if r.byMonthDay, err = parseInts(value, 1, 31); err != nil {
+				return r, err
+			}
+		case "BYSETPOS":
+			if r.bySetPos, err = parseInts(value, 1, 366); err != nil {
+				return r, err
+			}
+		case "BYDAY":
+			for _, d := range strings.Split(value, ",") {
+				if len(d) < 2 {
+					return r, fmt.Errorf("bad weekday %q", d)
+				}
+				wd, ok := rruleWeekdays[d[len(d)-2:]]
+				if !ok {
+					return r, fmt.Errorf("bad weekday %q", d)
+				}
+				n := 0
+				if len(d) > 2 {
+					if n, err = strconv.Atoi(d[:len(d)-2]); err != nil || n == 0 || n > 53 || n < -53 {
+						return r, fmt.Errorf("bad weekday %q", d)
+					}
+				}
+				r.byDay = append(r.byDay, weekdayNum{n, wd})
+			}
+		case "WKST":
+			wd, ok := rruleWeekdays[value]
+			if !ok {
+				return r, fmt.Errorf("bad weekday %q", value)
+			}
+			r.wkst = wd
+		default:
+			return r, fmt.Errorf("unsupported rule part %q", key)
+		}
+	}
+	if r.freq == "" {
+		return r, fmt.Errorf("rule without FREQ")
+	}
+	return r, nil
+}
+
+// parseInts parses a comma separated list of numbers between
+// -max and max, except 0.
+func parseInts(s string, min int, max int) (out []int, err error) {
+	for _, f := range strings.Split(s, ",") {
+		n, err := strconv.Atoi(f)
+		if err != nil || n == 0 || n > max || n < -max || (n > 0 && n < min) {
+			return nil, fmt.Errorf("bad number %q", f)
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+// parseRuleTime parses the end of a rule, either a date like
+// 20261231 or 2026-12-31 or a moment like 20261231T235959Z.
+func parseRuleTime(s string) (t time.Time, dateOnly bool, err error) {
+	for _, layout := range []string{"20060102", "2006-01-02"} {
+		if t, err = time.Parse(layout, s); err == nil {
+			return t, true, nil
+		}
+	}
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405"} {
+		if t, err = time.Parse(layout, s); err == nil {
+			return t, false, nil
+		}
+	}
+	return t, false, fmt.Errorf("bad end %q", s)
+}
+
+// between returns the occurrences of the rule for the first
+// occurrence start that fall between from (inclusive) and to
+// (exclusive). The occurrences have the time of day and the
+// location of start.
+func (r recurrence) between(start, from, to time.Time) (out []time.Time) {
+	loc := start.Location()
+	first := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+	until := r.until
+	if r.untilDate {
+		until = time.Date(until.Year(), until.Month(), until.Day(), 23, 59, 59, 0, loc)
+	}
+	n := 0
+	for p := 0; ; p += r.interval {
+		periodStart, days := r.period(first, p)
+		if !periodStart.Before(to) {
+			return out
+		}
+		for _, d := range days {
+			if d.Before(first) {
+				continue
+			}
+			t := time.Date(d.Year(), d.Month(), d.Day(), start.Hour(), start.Minute(), start.Second(), 0, loc)
+			if !until.IsZero() && t.After(until) {
+				return out
+			}
+			n++
+			if r.count > 0 && n > r.count {
+				return out
+			}
+			if !t.Before(from) && t.Before(to) {
+				out = append(out, t)
+			}
+		}
+	}
+}
+
+// anchor returns the first occurrence of a rule without a start:
+// the first day of the calendar, moved back by whole intervals to a
+// week before it for the days of the previous month in the grid. All
+// views of the calendar then have the same days.
+func (r recurrence) anchor(first time.Time) time.Time {
+	lead := first.AddDate(0, 0, -7)
+	t := first
+	for t.After(lead) {
+		switch r.freq {
+		case "DAILY":
+			t = t.AddDate(0, 0, -r.interval)
+		case "WEEKLY":
+			t = t.AddDate(0, 0, -7*r.interval)
+		case "MONTHLY":
+			t = t.AddDate(0, -r.interval, 0)
+		default:
+			t = t.AddDate(-r.interval, 0, 0)
+		}
+	}
+	return t
+}
+
+// period returns the first day of the period p (counted in units of
+// the frequency from the period of first) and the days of the rule
+// in it, in order.
+func (r recurrence) period(first time.Time, p int) (periodStart time.Time, days []time.Time) {
+	loc := first.Location()
+	switch r.freq {
+	case "DAILY":
+		periodStart = first.AddDate(0, 0, p)
+		days = []time.Time{periodStart}
+	case "WEEKLY":
+		weekStart := first.AddDate(0, 0, -((int(first.Weekday()) - int(r.wkst) + 7) % 7))
+		periodStart = weekStart.AddDate(0, 0, 7*p)
+		if len(r.byDay) == 0 {
+			days = []time.Time{periodStart.AddDate(0, 0, (int(first.Weekday())-int(r.wkst)+7)%7)}
+		}
+		for _, bd := range r.byDay {
+			days = append(days, periodStart.AddDate(0, 0, (int(bd.wd)-int(r.wkst)+7)%7))
+		}
+	case "MONTHLY":
+		periodStart = time.Date(first.Year(), first.Month()+time.Month(p), 1, 0, 0, 0, 0, loc)
+		days = r.monthDays(periodStart.Year(), periodStart.Month(), first.Day(), loc)
+	case "YEARLY":
+		periodStart = time.Date(first.Year()+p, 1, 1, 0, 0, 0, 0, loc)
+		y := periodStart.Year()
+		switch {
+		case len(r.byMonth) > 0:
+			for _, m := range r.byMonth {
+				days = append(days, r.monthDays(y, time.Month(m), first.Day(), loc)...)
+			}
+		case len(r.byMonthDay) > 0:
+			for m := time.January; m <= time.December; m++ {
+				days = append(days, r.monthDays(y, m, first.Day(), loc)...)
+			}
+		case len(r.byDay) > 0:
+			days = weekdaysIn(r.byDay, periodStart, periodStart.AddDate(1, 0, 0))
+		default:
+			if d := time.Date(y, first.Month(), first.Day(), 0, 0, 0, 0, loc); d.Day() == first.Day() {
+				days = []time.Time{d}
+			}
+		}
+	}
+	days = r.filter(days)
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	if len(r.bySetPos) > 0 {
+		var sel []time.Time
+		for _, pos := range r.bySetPos {
+			if pos > 0 && pos <= len(days) {
+				sel = append(sel, days[pos-1])
+			} else if pos < 0 && -pos <= len(days) {
+				sel = append(sel, days[len(days)+pos])
+			}
+		}
+		sort.Slice(sel, func(i, j int) bool { return sel[i].Before(sel[j]) })
+		days = sel
+	}
+	return periodStart, days
+}
+
+// monthDays returns the days of the rule in the month m, or the day
+// def if the rule names neither days of the month nor weekdays.
+func (r recurrence) monthDays(y int, m time.Month, def int, loc *time.Location) (days []time.Time) {
+	begin := time.Date(y, m, 1, 0, 0, 0, 0, loc)
+	end := begin.AddDate(0, 1, 0)
+	length := end.AddDate(0, 0, -1).Day()
+	switch {
+	case len(r.byMonthDay) > 0:
+		for _, n := range r.byMonthDay {
+			if n < 0 {
+				n += length + 1
+			}
+			if n >= 1 && n <= length {
+				days = append(days, time.Date(y, m, n, 0, 0, 0, 0, loc))
+			}
+		}
+	case len(r.byDay) > 0:
+		days = weekdaysIn(r.byDay, begin, end)
+	default:
+		if def <= length {
+			days = []time.Time{time.Date(y, m, def, 0, 0, 0, 0, loc)}
+		}
+	}
+	return days
+}
+
+// weekdaysIn returns the days from begin to before end that match
+// one of the weekdays wds. A weekday with a number matches only the
+// n-th such weekday, counted from the end if n is negative.
+func weekdaysIn(wds []weekdayNum, begin, end time.Time) (days []time.Time) {
+	for _, bd := range wds {
+		var all []time.Time
+		for d := begin.AddDate(0, 0, (int(bd.wd)-int(begin.Weekday())+7)%7); d.Before(end); d = d.AddDate(0, 0, 7) {
+			all = append(all, d)
+		}
+		switch {
+		case bd.n == 0:
+			days = append(days, all...)
+		case bd.n > 0 && bd.n <= len(all):
+			days = append(days, all[bd.n-1])
+		case bd.n < 0 && -bd.n <= len(all):
+			days = append(days, all[len(all)+bd.n])
+		}
+	}
+	return days
+}
+
+// filter drops the days that do not match the parts of the rule
+// which only limit the days of the period.
+func (r recurrence) filter(days []time.Time) (out []time.Time) {
+	seen := map[time.Time]bool{}
+	for _, d := range days {
+		if seen[d] {
+			continue
+		}
+		seen[d] = true
+		if len(r.byMonth) > 0 && !containsInt(r.byMonth, int(d.Month())) {
+			continue
+		}
+		if r.freq == "DAILY" || r.freq == "WEEKLY" {
+			if len(r.byMonthDay) > 0 && !matchesMonthDay(r.byMonthDay, d) {
+				continue
+			}
+		}
+		if len(r.byDay) > 0 && (r.freq == "DAILY" || len(r.byMonthDay) > 0) && !matchesWeekday(r.byDay, d) {
+			continue
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+func containsInt(list []int, n int) bool {
+	for _, v := range list {
+		if v == n {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesMonthDay(list []int, d time.Time) bool {
+	length := time.Date(d.Year(), d.Month()+1, 0, 0, 0, 0, 0, d.Location()).Day()
+	for _, n := range list {
+		if n == d.Day() || n == d.Day()-length-1 {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesWeekday(list []weekdayNum, d time.Time) bool {
+	for _, bd := range list {
+		if bd.wd == d.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+// easterDate matches the dates relative to Easter Sunday in the event
+// file, e.g. Easter, Easter+39 or Easter-2.
+var easterDate = regexp.MustCompile(`^(?i)easter([+-]\d+)?$`)
+
+// easterEvents returns the days off days after Easter Sunday between
+// from (inclusive) and to (exclusive).
+func easterEvents(off int, from, to time.Time) (out []time.Time) {
+	for y := from.Year(); y <= to.Year(); y++ {
+		for _, d := range easterDay(off)(y) {
+			if !d.Before(from) && d.Before(to) {
+				out = append(out, d)
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,280 @@
+package gocal
+
+// Copyright (c) 2014 Stefan Schroeder, NY, 2014-03-10
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file
+//
+// rtl.go
+//
+// This file is part of gocal, a PDF calendar generator in Go.
+//
+// https://github.com/StefanSchroeder/Gocal
+//
+
+import (
+	"unicode"
+)
+
+// rtlNames holds the month and weekday names of the right-to-left
+// locales, which the monday package does not know. The weekdays
+// start with Sunday.
+var rtlNames = map[string]struct {
+	months   [12]string
+	weekdays [7]string
+}{
+	"he_IL": {
+		[12]string{"ינואר", "פברואר", "מרץ", "אפריל", "מאי", "יוני", "יולי", "אוגוסט", "ספטמבר", "אוקטובר", "נובמבר", "דצמבר"},
+		[7]string{"ראשון", "שני", "שלישי", "רביעי", "חמישי", "שישי", "שבת"},
+	},
+	"ar_SA": {
+		[12]string{"يناير", "فبراير", "مارس", "أبريل", "مايو", "يونيو", "يوليو", "أغسطس", "سبتمبر", "أكتوبر", "نوفمبر", "ديسمبر"},
+		[7]string{"الأحد", "الاثنين", "الثلاثاء", "الأربعاء", "الخميس", "الجمعة", "السبت"},
+	},
+	"ar_EG": {
+		[12]string{"يناير", "فبراير", "مارس", "أبريل", "مايو", "يونيو", "يوليو", "أغسطس", "سبتمبر", "أكتوبر", "نوفمبر", "ديسمبر"},
+		[7]string{"الأحد", "الاثنين", "الثلاثاء", "الأربعاء", "الخميس", "الجمعة", "السبت"},
+	},
+	"fa_IR": {
+		[12]string{"ژانویه", "فوریه", "مارس", "آوریل", "مه", "ژوئن", "ژوئیه", "اوت", "سپتامبر", "اکتبر", "نوامبر", "دسامبر"},
+		[7]string{"یکشنبه", "دوشنبه", "سه‌شنبه", "چهارشنبه", "پنجشنبه", "جمعه", "شنبه"},
+	},
+}
+
+// rtl tells if the calendar is laid out from right to left. Unless
+// the direction was set explicitly, it follows the locale.
+func (g *Calendar) rtl(locale string) bool {
+	switch g.OptDirection {
+	case "rtl":
+		return true
+	case "ltr":
+		return false
+	}
+	_, ok := rtlNames[locale]
+	return ok
+}
+
+// mirrorAlign swaps left and right in a gofpdf alignment string
+// when rtl is set.
+func mirrorAlign(align string, rtl bool) string {
+	if !rtl {
+		return align
+	}
+	out := []byte(align)
+	for i, c := range out {
+		switch c {
+		case 'L':
+			out[i] = 'R'
+		case 'R':
+			out[i] = 'L'
+		}
+	}
+	return string(out)
+}
+
+// mirrorColumn returns the position of column col of n columns,
+// counted from the left of the page.
+func mirrorColumn(col int, n int, rtl bool) int {
+	if rtl {
+		return n - 1 - col
+	}
+	return col
+}
+
+// isRTL tells if r is a letter of a right-to-left script. The
+// Arabic-Indic digits are written left to right.
+func isRTL(r rune) bool {
+	return !unicode.IsDigit(r) && unicode.In(r, unicode.Hebrew, unicode.Arabic)
+}
+
+// visual reorders the logical string s for display, since gofpdf
+// draws all text from left to right. Runs of Hebrew and Arabic
+// letters are reversed, and a string that begins with such a letter
+// is read from the right, so that the runs also change places.
+// Digits and Latin words keep their order. Arabic letters are
+// replaced by their joined forms. Strings without right-to-left
+// letters are returned unchanged.
+func visual(s string) string {
+	in := []rune(s)
+	found := false
+	for _, r := range in {
+		if isRTL(r) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return s
+	}
+	paraRTL := false
+	for _, r := range in {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			paraRTL = isRTL(r)
+			break
+		}
+	}
+	in = shapeArabic(in)
+
+	// Classify: 1 right-to-left, -1 left-to-right, 0 neutral.
+	dir := make([]int, len(in))
+	for i, r := range in {
+		switch {
+		case isRTL(r):
+			dir[i] = 1
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			dir[i] = -1
+		}
+	}
+	// Neutrals between two letters of the same direction take
+	// that direction, the others that of the paragraph.
+	para := -1
+	if paraRTL {
+		para = 1
+	}
+	for i := 0; i < len(dir); i++ {
+		if dir[i] != 0 {
+			continue
+		}
+		j := i
+		for j < len(dir) && dir[j] == 0 {
+			j++
+		}
+		d := para
+		if i > 0 && j < len(dir) && dir[i-1] == dir[j] {
+			d = dir[j]
+		}
+		for k := i; k < j; k++ {
+			dir[k] = d
+		}
+		i = j - 1
+	}
+
+	var runs [][]rune
+	for i := 0; i < len(in); {
+		j := i
+		for j < len(in) && dir[j] == dir[i] {
+			j++
+		}
+		run := append([]rune{}, in[i:j]...)
+		if dir[i] == 1 {
+			for a, b := 0, len(run)-1; a < b; a, b = a+1, b-1 {
+				run[a], run[b] = run[b], run[a]
+			}
+			for k, r := range run {
+				switch r {
+				case '(':
+					run[k] = ')'
+				case ')':
+					run[k] = '('
+				}
+			}
+		}
+		runs = append(runs, run)
+		i = j
+	}
+	if paraRTL {
+		for a, b := 0, len(runs)-1; a < b; a, b = a+1, b-1 {
+			runs[a], runs[b] = runs[b], runs[a]
+		}
+	}
+	var out []rune
+	for _, run := range runs {
+		out = append(out, run...)
+	}
+	return string(out)
+}
+
+// arabicForms maps an Arabic letter to its isolated presentation
+// form and the number of forms: 2 for letters that join only to
+// the preceding letter, 4 for letters that join on both sides.
+// The forms follow each other in the order isolated, final,
+// initial, medial.
+var arabicForms = map[rune][2]rune{
+	0x0622: {0xFE81, 2}, 0x0623: {0xFE83, 2}, 0x0624: {0xFE85, 2},
+	0x0625: {0xFE87, 2}, 0x0626: {0xFE89, 4}, 0x0627: {0xFE8D, 2},
+	0x0628: {0xFE8F, 4}, 0x0629: {0xFE93, 2}, 0x062A: {0xFE95, 4},
+	0x062B: {0xFE99, 4}, 0x062C: {0xFE9D, 4}, 0x062D: {0xFEA1, 4},
+	0x062E: {0xFEA5, 4}, 0x062F: {0xFEA9, 2}, 0x0630: {0xFEAB, 2},
+	0x0631: {0xFEAD, 2}, 0x0632: {0xFEAF, 2}, 0x0633: {0xFEB1, 4},
+	0x0634: {0xFEB5, 4}, 0x0635: {0xFEB9, 4}, 0x0636: {0xFEBD, 4},
+	0x0637: {0xFEC1, 4}, 0x0638: {0xFEC5, 4}, 0x0639: {0xFEC9, 4},
+	0x063A: {0xFECD, 4}, 0x0641: {0xFED1, 4}, 0x0642: {0xFED5, 4},
+	0x0643: {0xFED9, 4}, 0x0644: {0xFEDD, 4}, 0x0645: {0xFEE1, 4},
+	0x0646: {0xFEE5, 4}, 0x0647: {0xFEE9, 4}, 0x0648: {0xFEED, 2},
+	0x0649: {0xFEEF, 2}, 0x064A: {0xFEF1, 4},
+	0x067E: {0xFB56, 4}, 0x0686: {0xFB7A, 4}, 0x0698: {0xFB8A, 2},
+	0x06A9: {0xFB8E, 4}, 0x06AF: {0xFB92, 4}, 0x06CC: {0xFBFC, 4},
+}
+
+// lamAlef maps the alef that follows a lam to the isolated form of
+// the ligature.
+var lamAlef = map[rune]rune{
+	0x0622: 0xFEF5, 0x0623: 0xFEF7, 0x0625: 0xFEF9, 0x0627: 0xFEFB,
+}
+
+// isHaraka tells if r is an Arabic vowel mark, which does not break
+// the joining of letters.
+func isHaraka(r rune) bool {
+	return r >= 0x064B && r <= 0x0652
+}
+
+// shapeArabic replaces the Arabic letters in s by the presentation
+// forms that join them to their neighbors. The zero width non-joiner
+// breaks the joining and is dropped.
+func shapeArabic(s []rune) []rune {
+	// joinsNext tells if the letter at i connects to the next one.
+	joinsNext := func(i int) bool {
+		f, ok := arabicForms[s[i]]
+		return (ok && f[1] == 4) || s[i] == 0x0640
+	}
+	var out []rune
+	prevJoins := false
+	for i := 0; i < len(s); i++ {
+		r := s[i]
+		if isHaraka(r) {
+			out = append(out, r)
+			continue
+		}
+		f, ok := arabicForms[r]
+		if !ok {
+			if r != 0x200C {
+				out = append(out, r)
+			}
+			prevJoins = r == 0x0640
+			continue
+		}
+		next := i + 1
+		for next < len(s) && isHaraka(s[next]) {
+			next++
+		}
+		if r == 0x0644 && next < len(s) {
+			if lig, ok := lamAlef[s[next]]; ok {
+				if prevJoins {
+					lig++
+				}
+				out = append(out, lig)
+				prevJoins = false
+				i = next
+				continue
+			}
+		}
+		nextArabic := false
+		if next < len(s) {
+			_, nextArabic = arabicForms[s[next]]
+			nextArabic = nextArabic || s[next] == 0x0640
+		}
+		joinNext := joinsNext(i) && nextArabic
+		form := f[0]
+		switch {
+		case prevJoins && joinNext:
+			form += 3
+		case joinNext:
+			form += 2
+		case prevJoins:
+			form++
+		}
+		out = append(out, form)
+		prevJoins = joinNext
+	}
+	return out
+}
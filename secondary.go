@@ -0,0 +1,346 @@
+package gocal
+
+// Copyright (c) 2014 Stefan Schroeder, NY, 2014-03-10
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file
+//
+// secondary.go
+//
+// This file is part of gocal, a PDF calendar generator in Go.
+//
+// https://github.com/StefanSchroeder/Gocal
+//
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/soniakeys/meeus/v3/base"
+	"github.com/soniakeys/meeus/v3/deltat"
+	"github.com/soniakeys/meeus/v3/jm"
+	"github.com/soniakeys/meeus/v3/julian"
+	"github.com/soniakeys/meeus/v3/moonphase"
+	"github.com/soniakeys/meeus/v3/solar"
+	"github.com/soniakeys/meeus/v3/solstice"
+)
+
+// A DateProvider converts a day of the Gregorian calendar to the
+// date in another calendar system, which is printed small in the
+// day cells. Format returns the text for the month calendar, or a
+// shorter text for the year calendars if short is set.
+type DateProvider interface {
+	Format(t time.Time, short bool) string
+}
+
+// JulianCalendar is the Julian calendar.
+type JulianCalendar struct{}
+
+// HebrewCalendar is the arithmetic Hebrew calendar. The date
+// is that of the daytime, the evening before belongs to it.
+type HebrewCalendar struct{}
+
+// HijriCalendar is the tabular Islamic calendar in its civil
+// form. Months that begin with the sighting of the crescent may
+// differ by a day.
+type HijriCalendar struct{}
+
+// ChineseCalendar is the Chinese lunisolar calendar. The months
+// begin with the new moon in China.
+type ChineseCalendar struct{}
+
+var secondaryCalendars = map[string]DateProvider{
+	"julian":  JulianCalendar{},
+	"hebrew":  HebrewCalendar{},
+	"hijri":   HijriCalendar{},
+	"chinese": ChineseCalendar{},
+}
+
+// SecondaryCalendar returns the bundled calendar system with the
+// given name, one of julian, hebrew, hijri and chinese, or nil.
+func SecondaryCalendar(name string) DateProvider {
+	return secondaryCalendars[name]
+}
+
+// dayText formats the day and the name of the month. The short
+// form has the name only on the first day of the month.
+func dayText(day int, month string, short bool) string {
+	if short && day != 1 {
+		return strconv.Itoa(day)
+	}
+	return fmt.Sprintf("%d %s", day, month)
+}
+
+// jdn returns the Julian day number of the day t.
+func jdn(t time.Time) int {
+	return int(julian.CalendarGregorianToJD(t.Year(), int(t.Month()), float64(t.Day())) + 0.5)
+}
+
+func (JulianCalendar) Format(t time.Time, short bool) string {
+	_, m, d := jm.GregorianToJulian(t.Year(), int(t.Month()), t.Day())
+	return dayText(d, time.Month(m).String()[:3], short)
+}
+
+// The Hebrew calendar after Calendrical Calculations by Reingold
+// and Dershowitz. The days are counted from 1 January 1 (Gregorian),
+// the months from Nisan, the year begins with Tishri.
+const hebrewEpoch = -1373427
+
+var hebrewMonths = [14]string{"", "Nisan", "Iyyar", "Sivan", "Tammuz", "Av",
+	"Elul", "Tishri", "Heshvan", "Kislev", "Tevet", "Shevat", "Adar", "Adar II"}
+
+func hebrewLeapYear(y int) bool {
+	return (7*y+1)%19 < 7
+}
+
+// hebrewElapsedDays returns the days from the epoch to the mean
+// new moon of Tishri of the year y, moved off Sunday, Wednesday
+// and Friday.
+func hebrewElapsedDays(y int) int {
+	months := (235*y - 234) / 19
+	parts := 12084 + 13753*months
+	day := 29*months + parts/25920
+	if (3*(day+1))%7 < 3 {
+		day++
+	}
+	return day
+}
+
+func hebrewNewYear(y int) int {
+	ny0, ny1, ny2 := hebrewElapsedDays(y-1), hebrewElapsedDays(y), hebrewElapsedDays(y+1)
+	delay := 0
+	if ny2-ny1 == 356 {
+		delay = 2
+	} else if ny1-ny0 == 382 {
+		delay = 1
+	}
+	return hebrewEpoch + ny1 + delay
+}
+
+func hebrewMonthDays(y int, m int) int {
+	yearDays := hebrewNewYear(y+1) - hebrewNewYear(y)
+	switch {
+	case m == 2 || m == 4 || m == 6 || m == 10 || m == 13:
+		return 29
+	case m == 12 && !hebrewLeapYear(y):
+		return 29
+	case m == 8 && yearDays%10 != 5: // Heshvan is long in complete years
+		return 29
+	case m == 9 && yearDays%10 == 3: // Kislev is short in deficient years
+		return 29
+	}
+	return 30
+}
+
+func fixedFromHebrew(y int, m int, d int) int {
+	days := hebrewNewYear(y) + d - 1
+	last := 12
+	if hebrewLeapYear(y) {
+		last = 13
+	}
+	if m < 7 {
+		for mm := 7; mm <= last; mm++ {
+			days += hebrewMonthDays(y, mm)
+		}
+		for mm := 1; mm < m; mm++ {
+			days += hebrewMonthDays(y, mm)
+		}
+	} else {
+		for mm := 7; mm < m; mm++ {
+			days += hebrewMonthDays(y, mm)
+		}
+	}
+	return days
+}
+
+func hebrewFromFixed(date int) (y int, m int, d int) {
+	y = int(float64(date-hebrewEpoch) / (35975351.0 / 98496.0))
+	for hebrewNewYear(y+1) <= date {
+		y++
+	}
+	m = 7
+	if date >= fixedFromHebrew(y, 1, 1) {
+		m = 1
+	}
+	for date > fixedFromHebrew(y, m, hebrewMonthDays(y, m)) {
+		m++
+	}
+	d = date - fixedFromHebrew(y, m, 1) + 1
+	return
+}
+
+func (HebrewCalendar) Format(t time.Time, short bool) string {
+	y, m, d := hebrewFromFixed(jdn(t) - 1721425)
+	month := hebrewMonths[m]
+	if m == 12 && hebrewLeapYear(y) {
+		month = "Adar I"
+	}
+	return dayText(d, month, short)
+}
+
+// hijriEpoch is the Julian day number of 1 Muharram 1.
+const hijriEpoch = 1948440
+
+func jdnFromHijri(y int, m int, d int) int {
+	return d + (59*(m-1)+1)/2 + (y-1)*354 + base.FloorDiv(3+11*y, 30) + hijriEpoch - 1
+}
+
+func hijriFromJDN(n int) (y int, m int, d int) {
+	y = base.FloorDiv(30*(n-hijriEpoch)+10646, 10631)
+	m = 1
+	for m < 12 && n >= jdnFromHijri(y, m+1, 1) {
+		m++
+	}
+	d = n - jdnFromHijri(y, m, 1) + 1
+	return
+}
+
+func (HijriCalendar) Format(t time.Time, short bool) string {
+	_, m, d := hijriFromJDN(jdn(t))
+	return dayText(d, jm.MMonth(m).String(), short)
+}
+
+var chineseMonths = [13]string{"", "Zhēngyuè", "Èryuè", "Sānyuè", "Sìyuè",
+	"Wǔyuè", "Liùyuè", "Qīyuè", "Bāyuè", "Jiǔyuè", "Shíyuè", "Dōngyuè", "Làyuè"}
+
+// synodicYears is the mean length of a lunation in years.
+const synodicYears = 29.530588853 / 365.25
+
+// deltaT returns the difference TT-UT in days.
+func deltaT(jde float64) float64 {
+	y := base.JDEToJulianYear(jde)
+	switch {
+	case y >= 2010:
+		return deltat.PolyAfter2000(y).Sec() / 86400
+	case y >= 1620:
+		return deltat.Interp10A(jde).Sec() / 86400
+	case y >= 948:
+		return deltat.Poly948to1600(y).Sec() / 86400
+	}
+	return deltat.PolyBefore948(y).Sec() / 86400
+}
+
+// localDay returns the Julian day number of the day at the moment
+// jde in the time zone that is hours ahead of UTC.
+func localDay(jde float64, hours float64) int {
+	return int(math.Floor(jde - deltaT(jde) + hours/24 + 0.5))
+}
+
+// chinaDay returns the Julian day number of the day in China
+// (UTC+8) at the moment jde.
+func chinaDay(jde float64) int {
+	return localDay(jde, 8)
+}
+
+// newMoonOnOrBefore returns the day of the last new moon on or
+// before the day n in China.
+func newMoonOnOrBefore(n int) int {
+	y := base.JDEToJulianYear(float64(n))
+	nm := chinaDay(moonphase.New(y))
+	for nm > n {
+		y -= synodicYears
+		nm = chinaDay(moonphase.New(y))
+	}
+	for {
+		next := chinaDay(moonphase.New(y + synodicYears))
+		if next > n {
+			return nm
+		}
+		y += synodicYears
+		nm = next
+	}
+}
+
+// majorTerm returns the number of the 30° segment of the solar
+// longitude at the beginning of the day n in China.
+func majorTerm(n int) int {
+	jde := float64(n) - 0.5 - 8.0/24
+	jde += deltaT(jde)
+	lon := math.Mod(solar.ApparentLongitude(base.J2000Century(jde)).Deg(), 360)
+	if lon < 0 {
+		lon += 360
+	}
+	return int(lon / 30)
+}
+
+// chineseMonth11 returns the first day of the eleventh month, which
+// contains the winter solstice of the year y.
+func chineseMonth11(y int) int {
+	return newMoonOnOrBefore(chinaDay(solstice.December(y)))
+}
+
+// chineseMonth is a month of the Chinese calendar.
+type chineseMonth struct {
+	start  int // Julian day number of the first day
+	number int
+	leap   bool
+}
+
+// chineseSui returns the months from the eleventh month before the
+// year y to the eleventh month of y and the first day of the latter.
+// In a sui of 13 months the first month without a major solar term
+// is a leap month that repeats the number of the month before.
+func chineseSui(y int) (months []chineseMonth, end int) {
+	a, b := chineseMonth11(y-1), chineseMonth11(y)
+	moons := []int{a}
+	for moons[len(moons)-1] < b {
+		moons = append(moons, newMoonOnOrBefore(moons[len(moons)-1]+30))
+	}
+	leapYear := len(moons) == 14
+
+	m := 11
+	leapDone := false
+	for i := 0; i < len(moons)-1; i++ {
+		leap := false
+		if i > 0 {
+			if leapYear && !leapDone && majorTerm(moons[i]) == majorTerm(moons[i+1]) {
+				leap = true
+				leapDone = true
+			} else {
+				m = m%12 + 1
+			}
+		}
+		months = append(months, chineseMonth{moons[i], m, leap})
+	}
+	return months, b
+}
+
+// chineseFromJDN returns the month and day of the Chinese calendar
+// for the Julian day number n.
+func chineseFromJDN(n int) (m int, leap bool, d int) {
+	gy, _, _ := julian.JDToCalendar(float64(n))
+	months, end := chineseSui(gy)
+	if n >= end {
+		months, _ = chineseSui(gy + 1)
+	}
+	cm := months[0]
+	for _, mo := range months {
+		if mo.start <= n {
+			cm = mo
+		}
+	}
+	return cm.number, cm.leap, n - cm.start + 1
+}
+
+// jdnFromChinese returns the Julian day number of the day d of the
+// month m (1 to 10) of the Chinese year that begins in the year y.
+func jdnFromChinese(y int, m int, d int) int {
+	months, _ := chineseSui(y)
+	for _, mo := range months {
+		if mo.number == m && !mo.leap {
+			return mo.start + d - 1
+		}
+	}
+	return 0
+}
+
+func (ChineseCalendar) Format(t time.Time, short bool) string {
+	m, leap, d := chineseFromJDN(jdn(t))
+	month := chineseMonths[m]
+	if leap {
+		month = "Rùn " + month
+	}
+	return dayText(d, month, short)
+}
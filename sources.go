@@ -0,0 +1,99 @@
+package gocal
+
+// Copyright (c) 2014 Stefan Schroeder, NY, 2014-03-10
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file
+//
+// sources.go
+//
+// This file is part of gocal, a PDF calendar generator in Go.
+//
+// https://github.com/StefanSchroeder/Gocal
+//
+
+import (
+	"bytes"
+	"time"
+)
+
+// EventSource provides events, e.g. from a database. Events returns
+// the events that happen on the days from from to before to. Events
+// without a year, or with a weekday, repeat and are returned once.
+type EventSource interface {
+	Events(from, to time.Time) ([]Event, error)
+}
+
+// EventSourceFunc is a function that is an EventSource.
+type EventSourceFunc func(from, to time.Time) ([]Event, error)
+
+// Events calls f(from, to).
+func (f EventSourceFunc) Events(from, to time.Time) ([]Event, error) {
+	return f(from, to)
+}
+
+// XMLSource returns the EventSource of the XML event file filename,
+// with the settings of the calendar, e.g. the logger. AddConfig is
+// AddSource(g.XMLSource(filename)).
+func (g *Calendar) XMLSource(filename string) EventSource {
+	return EventSourceFunc(func(from, to time.Time) ([]Event, error) {
+		return g.readConfigurationfile(filename, from, to)
+	})
+}
+
+// ICSSource returns the EventSource of the ICS file or feed
+// filename, with the settings of the calendar, e.g. the time zone
+// and the cache. AddICS is AddSource(g.ICSSource(filename)).
+func (g *Calendar) ICSSource(filename string) EventSource {
+	return EventSourceFunc(func(from, to time.Time) ([]Event, error) {
+		loc, err := g.location()
+		if err != nil {
+			return nil, err
+		}
+		return g.readICSfile(filename, from, to, loc)
+	})
+}
+
+// sources returns the sources of the events: the event file of
+// SetConfig, the ICS files and readers, the event files of AddConfig,
+// the CSV, JSON and vCard files, AddEvent and AddSource.
+func (g *Calendar) sources() (list []EventSource) {
+	if g.OptConfig != "" {
+		list = append(list, g.XMLSource(g.OptConfig))
+	}
+	for _, f := range g.OptICS {
+		list = append(list, g.ICSSource(f))
+	}
+	for _, d := range g.OptICSData {
+		d := d
+		list = append(list, EventSourceFunc(func(from, to time.Time) ([]Event, error) {
+			if d.err != nil {
+				return nil, &Error{ErrICS, "", d.err}
+			}
+			loc, err := g.location()
+			if err != nil {
+				return nil, err
+			}
+			return g.readICS("", bytes.NewReader(d.data), from, to, loc)
+		}))
+	}
+	for _, f := range g.OptConfigs {
+		list = append(list, g.XMLSource(f))
+	}
+	for _, src := range g.OptTables {
+		src := src
+		list = append(list, EventSourceFunc(func(from, to time.Time) ([]Event, error) {
+			return g.readTable(src, from, to)
+		}))
+	}
+	for _, f := range g.OptVCards {
+		f := f
+		list = append(list, EventSourceFunc(func(from, to time.Time) ([]Event, error) {
+			return g.readVCardfile(f)
+		}))
+	}
+	list = append(list, EventSourceFunc(func(from, to time.Time) ([]Event, error) {
+		return g.EventList, nil
+	}))
+	return append(list, g.OptSources...)
+}
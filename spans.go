@@ -0,0 +1,108 @@
+package gocal
+
+// Copyright (c) 2014 Stefan Schroeder, NY, 2014-03-10
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file
+//
+// spans.go
+//
+// This file is part of gocal, a PDF calendar generator in Go.
+//
+// https://github.com/StefanSchroeder/Gocal
+//
+
+import (
+	"sort"
+	"time"
+)
+
+// span is a multi-day event placed in a lane. Events that overlap
+// are in different lanes, drawn one below the other.
+type span struct {
+	first time.Time // first day
+	last  time.Time // last day, inclusive
+	lane  int
+	ev    Event
+}
+
+// spanEvent returns the event from the day first to the day last.
+func spanEvent(first, last time.Time, text string, image string) Event {
+	first = time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, time.UTC)
+	last = time.Date(last.Year(), last.Month(), last.Day(), 0, 0, 0, 0, time.UTC)
+	days := int(last.Sub(first).Hours()/24) + 1
+	if days < 1 {
+		days = 1
+	}
+	return Event{Month: first.Month(), Day: first.Day(), Text: text, Image: image, Year: first.Year(), Days: days}
+}
+
+// yearlySpans returns the multi-day event ev of every year, one for
+// each year in which it overlaps the days from from to before to.
+func yearlySpans(ev Event, from, to time.Time) (evs []Event) {
+	for y := from.Year() - 1; y <= to.Year(); y++ {
+		first := time.Date(y, ev.Month, ev.Day, 0, 0, 0, 0, time.UTC)
+		last := first.AddDate(0, 0, ev.Days-1)
+		if first.Before(to) && !last.Before(from) {
+			ev.Year = y
+			evs = append(evs, ev)
+		}
+	}
+	return evs
+}
+
+// spanLanes returns the multi-day events that overlap the days from
+// from to before to and the number of lanes they need.
+func spanLanes(events []Event, from, to time.Time) (spans []span, lanes int) {
+	for _, ev := range events {
+		if ev.Days <= 1 || ev.Year == 0 {
+			continue
+		}
+		first := time.Date(ev.Year, ev.Month, ev.Day, 0, 0, 0, 0, time.UTC)
+		last := first.AddDate(0, 0, ev.Days-1)
+		if first.Before(to) && !last.Before(from) {
+			spans = append(spans, span{first, last, 0, ev})
+		}
+	}
+	// Longer events first, so that they get the upper lanes.
+	sort.SliceStable(spans, func(i, j int) bool {
+		if !spans[i].first.Equal(spans[j].first) {
+			return spans[i].first.Before(spans[j].first)
+		}
+		return spans[i].last.After(spans[j].last)
+	})
+	var laneEnd []time.Time
+	for i := range spans {
+		lane := 0
+		for lane < len(laneEnd) && !laneEnd[lane].Before(spans[i].first) {
+			lane++
+		}
+		if lane == len(laneEnd) {
+			laneEnd = append(laneEnd, time.Time{})
+		}
+		laneEnd[lane] = spans[i].last
+		spans[i].lane = lane
+	}
+	return spans, len(laneEnd)
+}
+
+// spansOn returns the spans that include the day t.
+func spansOn(spans []span, t time.Time) (out []span) {
+	for _, s := range spans {
+		if !t.Before(s.first) && !t.After(s.last) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// lanesIn returns the number of lanes used by the spans that
+// overlap the days from first to last.
+func lanesIn(spans []span, first, last time.Time) (lanes int) {
+	for _, s := range spans {
+		if !s.first.After(last) && !s.last.Before(first) && s.lane >= lanes {
+			lanes = s.lane + 1
+		}
+	}
+	return lanes
+}
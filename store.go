@@ -0,0 +1,86 @@
+package gocal
+
+// Copyright (c) 2014 Stefan Schroeder, NY, 2014-03-10
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file
+//
+// store.go
+//
+// This file is part of gocal, a PDF calendar generator in Go.
+//
+// https://github.com/StefanSchroeder/Gocal
+//
+
+import (
+	"sort"
+	"time"
+)
+
+// eventStore holds the events of a calendar indexed by their days,
+// so that the events of a day are found without looking at all of
+// them. It is built once for every calendar that is created. The
+// indexes hold positions in the list of events, in the order of the
+// list.
+type eventStore struct {
+	events   []Event
+	dated    map[int][]int // events of one year by year*10000+month*100+day
+	yearly   map[int][]int // events of every year by month*100+day
+	weekly   [7][]int      // events of every week by time.Weekday
+	multiDay []Event       // events over several days
+}
+
+// newEventStore indexes the events. Events without text are left
+// out, as are events with an unknown weekday name.
+func newEventStore(events []Event) *eventStore {
+	s := &eventStore{
+		events: events,
+		dated:  map[int][]int{},
+		yearly: map[int][]int{},
+	}
+	weekdays := map[string]time.Weekday{}
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		weekdays[d.String()] = d
+	}
+	for i, ev := range events {
+		switch {
+		case len(ev.Text) == 0:
+		case ev.Days > 1:
+			if ev.Year != 0 {
+				s.multiDay = append(s.multiDay, ev)
+			}
+		case ev.Weekday != "":
+			if d, ok := weekdays[ev.Weekday]; ok {
+				s.weekly[d] = append(s.weekly[d], i)
+			}
+		case ev.Year != 0:
+			k := ev.Year*10000 + int(ev.Month)*100 + ev.Day
+			s.dated[k] = append(s.dated[k], i)
+		default:
+			k := int(ev.Month)*100 + ev.Day
+			s.yearly[k] = append(s.yearly[k], i)
+		}
+	}
+	return s
+}
+
+// on returns the single-day events on the day t, in the order of
+// the list of events.
+func (s *eventStore) on(t time.Time) []Event {
+	k := int(t.Month())*100 + t.Day()
+	dated, yearly, weekly := s.dated[t.Year()*10000+k], s.yearly[k], s.weekly[t.Weekday()]
+	n := len(dated) + len(yearly) + len(weekly)
+	if n == 0 {
+		return nil
+	}
+	idx := make([]int, 0, n)
+	idx = append(append(append(idx, dated...), yearly...), weekly...)
+	if n != len(dated) && n != len(yearly) && n != len(weekly) {
+		sort.Ints(idx) // from more than one index
+	}
+	out := make([]Event, len(idx))
+	for i, j := range idx {
+		out[i] = s.events[j]
+	}
+	return out
+}
@@ -0,0 +1,201 @@
+package gocal
+
+// Copyright (c) 2014 Stefan Schroeder, NY, 2014-03-10
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file
+//
+// style.go
+//
+// This file is part of gocal, a PDF calendar generator in Go.
+//
+// https://github.com/StefanSchroeder/Gocal
+//
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// CategoryStyle is the look of the events of a category. The colors
+// are written #rrggbb, an empty color is not used.
+type CategoryStyle struct {
+	Name  string `xml:"name,attr"`
+	Label string `xml:"label,attr"` // text in the legend, the name by default
+	Color string `xml:"color,attr"` // color of the text
+	Tint  string `xml:"tint,attr"`  // background of the day cell
+	Dot   string `xml:"dot,attr"`   // color of a dot before the text
+}
+
+// styleFile is the XML style file.
+type styleFile struct {
+	XMLName  xml.Name `xml:"GocalStyle"`
+	Category []CategoryStyle
+}
+
+// rgb is a color of the style.
+type rgb struct {
+	r, g, b int
+}
+
+// parseColor parses a color #rrggbb. The empty string is no color.
+func parseColor(s string) (c *rgb, err error) {
+	if s == "" {
+		return nil, nil
+	}
+	h := strings.TrimPrefix(s, "#")
+	n, err := strconv.ParseUint(h, 16, 32)
+	if err != nil || len(h) != 6 {
+		return nil, fmt.Errorf("bad color %q", s)
+	}
+	return &rgb{int(n >> 16), int(n >> 8 & 0xff), int(n & 0xff)}, nil
+}
+
+// style is a CategoryStyle with the colors parsed.
+type style struct {
+	label string
+	color *rgb
+	tint  *rgb
+	dot   *rgb
+}
+
+// styles returns the styles of the style file and of
+// AddCategoryStyle by the lower-case name of the category, and
+// the names in the order of their definition for the legend.
+func (g *Calendar) styles() (styles map[string]style, order []string, err error) {
+	all := []CategoryStyle{}
+	if g.OptStyle != "" {
+		data, err := ioutil.ReadFile(g.OptStyle)
+		if err != nil {
+			return nil, nil, &Error{ErrConfig, g.OptStyle, err}
+		}
+		var f styleFile
+		if err := xml.Unmarshal(data, &f); err != nil {
+			return nil, nil, &Error{ErrConfig, g.OptStyle, err}
+		}
+		all = append(all, f.Category...)
+	}
+	all = append(all, g.OptCategories...)
+
+	styles = map[string]style{}
+	for _, c := range all {
+		s := style{label: c.Label}
+		if s.label == "" {
+			s.label = c.Name
+		}
+		for _, p := range []struct {
+			dst **rgb
+			src string
+		}{{&s.color, c.Color}, {&s.tint, c.Tint}, {&s.dot, c.Dot}} {
+			if *p.dst, err = parseColor(p.src); err != nil {
+				return nil, nil, &Error{ErrConfig, g.OptStyle, fmt.Errorf("category %q: %v", c.Name, err)}
+			}
+		}
+		name := strings.ToLower(c.Name)
+		if _, ok := styles[name]; !ok {
+			order = append(order, name)
+		}
+		styles[name] = s
+	}
+	return styles, order, nil
+}
+
+// dayStyles returns the styles of the single-day events on the days
+// from from to before to, by the day in the format 2006-01-02.
+func dayStyles(store *eventStore, styles map[string]style, from, to time.Time) map[string][]style {
+	out := map[string][]style{}
+	if len(styles) == 0 {
+		return out
+	}
+	for t := from; t.Before(to); t = t.AddDate(0, 0, 1) {
+		for _, ev := range store.on(t) {
+			if s, ok := styles[strings.ToLower(ev.Category)]; ok {
+				out[t.Format("2006-01-02")] = append(out[t.Format("2006-01-02")], s)
+			}
+		}
+	}
+	return out
+}
+
+// tintOf returns the first background color of the styles.
+func tintOf(styles []style) *rgb {
+	for _, s := range styles {
+		if s.tint != nil {
+			return s.tint
+		}
+	}
+	return nil
+}
+
+// drawDots draws a row of small dots with the dot colors of the
+// styles, centered at x, y.
+func drawDots(pdf *gofpdf.Fpdf, styles []style, x, y, r float64) {
+	var dots []*rgb
+	for _, s := range styles {
+		if s.dot != nil {
+			dots = append(dots, s.dot)
+		}
+	}
+	x -= float64(len(dots)-1) * 1.5 * r
+	for i, c := range dots {
+		pdf.SetFillColor(c.r, c.g, c.b)
+		pdf.Circle(x+float64(i)*3*r, y, r, "F")
+	}
+	pdf.SetFillColor(LIGHTGREY, LIGHTGREY, LIGHTGREY)
+}
+
+// drawLegend draws a row of the styles with their labels from the
+// left at x, y, or from the right end at x in right-to-left mode.
+func drawLegend(pdf *gofpdf.Fpdf, styles map[string]style, order []string, x, y, fontSize float64, rtl bool) {
+	pdf.SetFontSize(fontSize)
+	size := fontSize / 3.0 // the swatch, as high as the text
+	for _, name := range order {
+		s := styles[name]
+		label := visual(s.label)
+		w := size + CELLMARGIN + pdf.GetStringWidth(label) + 3*CELLMARGIN
+		sx := x
+		if rtl {
+			sx = x - size
+		}
+		pdf.SetDrawColor(DARKGREY, DARKGREY, DARKGREY)
+		if s.tint != nil {
+			pdf.SetFillColor(s.tint.r, s.tint.g, s.tint.b)
+			pdf.Rect(sx, y-size, size, size, "FD")
+		} else {
+			pdf.Rect(sx, y-size, size, size, "D")
+		}
+		if s.dot != nil {
+			pdf.SetFillColor(s.dot.r, s.dot.g, s.dot.b)
+			pdf.Circle(sx+size/2, y-size/2, size/4, "F")
+		}
+		if s.color != nil {
+			pdf.SetTextColor(s.color.r, s.color.g, s.color.b)
+		} else {
+			pdf.SetTextColor(BLACK, BLACK, BLACK)
+		}
+		if rtl {
+			pdf.Text(sx-CELLMARGIN-pdf.GetStringWidth(label), y, label)
+			x -= w
+		} else {
+			pdf.Text(sx+size+CELLMARGIN, y, label)
+			x += w
+		}
+	}
+	pdf.SetDrawColor(BLACK, BLACK, BLACK)
+	pdf.SetFillColor(LIGHTGREY, LIGHTGREY, LIGHTGREY)
+}
+
+// setFill sets the fill color c, or the grey shade if c is nil.
+func setFill(pdf *gofpdf.Fpdf, c *rgb, grey int) {
+	if c == nil {
+		pdf.SetFillColor(grey, grey, grey)
+		return
+	}
+	pdf.SetFillColor(c.r, c.g, c.b)
+}
@@ -0,0 +1,214 @@
+package gocal
+
+// Copyright (c) 2014 Stefan Schroeder, NY, 2014-03-10
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file
+//
+// table.go
+//
+// This file is part of gocal, a PDF calendar generator in Go.
+//
+// https://github.com/StefanSchroeder/Gocal
+//
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldMap names the columns of a CSV file, or the fields of the
+// objects in a JSON file, that hold the parts of an event. Empty
+// names are the defaults date, text, image, category and end. The
+// values are written as the attributes of the XML file, unless
+// DateFormat is set.
+type FieldMap struct {
+	Date       string
+	Text       string
+	Image      string
+	Category   string
+	End        string
+	DateFormat string // layout of the date and the end for time.Parse, e.g. 02.01.2006
+}
+
+// tableSource is a CSV or JSON file given to AddCSV or AddJSON.
+type tableSource struct {
+	filename string
+	json     bool
+	fields   FieldMap
+}
+
+// ParseFieldMap parses a mapping like "date=Start,text=Name", which
+// gives the columns of the parts date, text, image, category and end.
+func ParseFieldMap(s string) (m FieldMap, err error) {
+	for _, kv := range strings.Split(s, ",") {
+		if strings.TrimSpace(kv) == "" {
+			continue
+		}
+		p := strings.SplitN(kv, "=", 2)
+		if len(p) != 2 {
+			return m, fmt.Errorf("bad field mapping %q", kv)
+		}
+		col := strings.TrimSpace(p[1])
+		switch strings.ToLower(strings.TrimSpace(p[0])) {
+		case "date":
+			m.Date = col
+		case "text":
+			m.Text = col
+		case "image":
+			m.Image = col
+		case "category":
+			m.Category = col
+		case "end":
+			m.End = col
+		default:
+			return m, fmt.Errorf("unknown field %q", p[0])
+		}
+	}
+	return m, nil
+}
+
+// withDefaults returns m with the default names for empty ones.
+func (m FieldMap) withDefaults() FieldMap {
+	for _, p := range []struct {
+		name *string
+		def  string
+	}{{&m.Date, "date"}, {&m.Text, "text"}, {&m.Image, "image"}, {&m.Category, "category"}, {&m.End, "end"}} {
+		if *p.name == "" {
+			*p.name = p.def
+		}
+	}
+	return m
+}
+
+// gocaldate returns the entry of a row, get returns the value of a
+// column. Dates in DateFormat are converted to YYYY-MM-DD.
+func (m FieldMap) gocaldate(get func(string) string) (e Gocaldate, err error) {
+	e = Gocaldate{
+		Date:     get(m.Date),
+		Text:     get(m.Text),
+		Image:    get(m.Image),
+		Category: get(m.Category),
+		End:      get(m.End),
+	}
+	if m.DateFormat == "" {
+		return e, nil
+	}
+	for _, d := range []*string{&e.Date, &e.End} {
+		if *d == "" {
+			continue
+		}
+		t, err := time.Parse(m.DateFormat, *d)
+		if err != nil {
+			return e, fmt.Errorf("date %q is not in the format %q", *d, m.DateFormat)
+		}
+		*d = t.Format("2006-01-02")
+	}
+	return e, nil
+}
+
+// readCSV returns the entries of CSV data. The first line names the
+// columns. The separator is a comma, or a semicolon if the first
+// line has more of those.
+func readCSV(data []byte, m FieldMap) (entries []Gocaldate, err error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // BOM of spreadsheets
+	r := csv.NewReader(bytes.NewReader(data))
+	header := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		header = data[:i]
+	}
+	if bytes.Count(header, []byte(";")) > bytes.Count(header, []byte(",")) {
+		r.Comma = ';'
+	}
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no header line")
+	}
+	cols := map[string]int{}
+	for i, name := range records[0] {
+		cols[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{m.Date, m.Text} {
+		if _, ok := cols[strings.ToLower(name)]; !ok {
+			return nil, fmt.Errorf("no column %q", name)
+		}
+	}
+	for n, rec := range records[1:] {
+		get := func(name string) string {
+			if i, ok := cols[strings.ToLower(name)]; ok && i < len(rec) {
+				return strings.TrimSpace(rec[i])
+			}
+			return ""
+		}
+		if get(m.Date) == "" && get(m.Text) == "" {
+			continue // empty line
+		}
+		e, err := m.gocaldate(get)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", n+2, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// readJSON returns the entries of JSON data, which is an array of
+// objects. Numbers are taken as text, e.g. for a column of years.
+func readJSON(data []byte, m FieldMap) (entries []Gocaldate, err error) {
+	var records []map[string]interface{}
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, err
+	}
+	for n, rec := range records {
+		get := func(name string) string {
+			switch v := rec[name].(type) {
+			case string:
+				return strings.TrimSpace(v)
+			case float64:
+				return strconv.FormatFloat(v, 'f', -1, 64)
+			case bool:
+				return strconv.FormatBool(v)
+			}
+			return ""
+		}
+		e, err := m.gocaldate(get)
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %v", n+1, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// readTable reads the CSV or JSON file of src and returns the
+// events for the days between from and to.
+func (g *Calendar) readTable(src tableSource, from, to time.Time) (eL []Event, err error) {
+	if g.excludedSource(src.filename, "") {
+		return nil, nil
+	}
+	data, err := ioutil.ReadFile(src.filename)
+	if err != nil {
+		return nil, &Error{ErrTable, src.filename, err}
+	}
+	var entries []Gocaldate
+	if src.json {
+		entries, err = readJSON(data, src.fields.withDefaults())
+	} else {
+		entries, err = readCSV(data, src.fields.withDefaults())
+	}
+	if err != nil {
+		return nil, &Error{ErrTable, src.filename, err}
+	}
+	return g.gocaldateEvents(src.filename, entries, from, to), nil
+}
@@ -0,0 +1,157 @@
+package gocal
+
+// Copyright (c) 2014 Stefan Schroeder, NY, 2014-03-10
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file
+//
+// vcard.go
+//
+// This file is part of gocal, a PDF calendar generator in Go.
+//
+// https://github.com/StefanSchroeder/Gocal
+//
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// appleNoYear is the year that Apple writes for dates without a year.
+const appleNoYear = 1604
+
+// The texts of the events of a vCard. The age is only added if the
+// year is known.
+const (
+	vcardBirthday        = "{name}"
+	vcardBirthdayAge     = "{name} ({age})"
+	vcardAnniversary     = "Anniversary {name}"
+	vcardAnniversaryYear = "Anniversary {name} ({years})"
+)
+
+// parseVCardDate parses a date of vCard 3 or 4, e.g. 1990-03-12,
+// 19900312 or --0312 without the year, which is then 0. A time of
+// day is ignored.
+func parseVCardDate(v string) (year int, month time.Month, day int, err error) {
+	if i := strings.Index(v, "T"); i >= 0 {
+		v = v[:i]
+	}
+	if strings.HasPrefix(v, "--") {
+		t, err := time.Parse("0102", strings.Replace(v[2:], "-", "", -1))
+		if err != nil {
+			return 0, 0, 0, fmt.Errorf("bad date %q", v)
+		}
+		return 0, t.Month(), t.Day(), nil
+	}
+	t, err := time.Parse("20060102", strings.Replace(v, "-", "", -1))
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("bad date %q", v)
+	}
+	return t.Year(), t.Month(), t.Day(), nil
+}
+
+// readVCard returns the yearly events of the birthdays (BDAY) and
+// anniversaries (ANNIVERSARY) of the contacts in the vCard data in r.
+// Dates that cannot be parsed, e.g. "circa 1800", are skipped and
+// reported to the logger.
+func (g *Calendar) readVCard(name string, r io.Reader) (eL []Event, err error) {
+	lines, err := unfoldICS(r)
+	if err != nil {
+		return nil, &Error{ErrVCard, name, err}
+	}
+	if len(lines) == 0 || strings.ToUpper(lines[0].text) != "BEGIN:VCARD" {
+		return nil, &Error{ErrVCard, name, fmt.Errorf("not a vCard file")}
+	}
+
+	type date struct {
+		value    string
+		birthday bool
+		omitYear string // of X-APPLE-OMIT-YEAR
+	}
+	var card bool
+	var fn, n string
+	var dates []date
+	for _, line := range lines {
+		p, err := parseICSLine(line.text)
+		if err != nil {
+			return nil, &Error{ErrVCard, name, fmt.Errorf("line %d: %v", line.n, err)}
+		}
+		// Apple writes item1.BDAY.
+		if i := strings.LastIndex(p.name, "."); i >= 0 {
+			p.name = p.name[i+1:]
+		}
+		switch {
+		case p.name == "BEGIN" && strings.EqualFold(p.value, "VCARD"):
+			if card {
+				return nil, &Error{ErrVCard, name, fmt.Errorf("line %d: VCARD inside VCARD", line.n)}
+			}
+			card, fn, n, dates = true, "", "", nil
+		case !card:
+		case p.name == "FN":
+			fn = icsText(p.value)
+		case p.name == "N":
+			// Family;Given;Additional;Prefix;Suffix
+			parts := strings.Split(p.value, ";")
+			n = parts[0]
+			if len(parts) > 1 {
+				n = parts[1] + " " + parts[0]
+			}
+			n = strings.TrimSpace(icsText(n))
+		case p.name == "BDAY" || p.name == "ANNIVERSARY" || p.name == "X-ANNIVERSARY":
+			if strings.EqualFold(p.params["VALUE"], "text") {
+				g.logf("%s: line %d: skipping date '%s'", name, line.n, p.value)
+				continue
+			}
+			dates = append(dates, date{p.value, p.name == "BDAY", p.params["X-APPLE-OMIT-YEAR"]})
+		case p.name == "END" && strings.EqualFold(p.value, "VCARD"):
+			card = false
+			if fn == "" {
+				fn = n
+			}
+			for _, d := range dates {
+				year, month, day, err := parseVCardDate(d.value)
+				if err != nil {
+					g.logf("%s: skipping %s: %v", name, fn, err)
+					continue
+				}
+				// Apple stands in a year for dates without one.
+				if year == appleNoYear || (d.omitYear != "" && d.omitYear == strconv.Itoa(year)) {
+					year = 0
+				}
+				var text, category string
+				switch {
+				case d.birthday && year != 0:
+					text, category = vcardBirthdayAge, "birthday"
+				case d.birthday:
+					text, category = vcardBirthday, "birthday"
+				case year != 0:
+					text, category = vcardAnniversaryYear, "anniversary"
+				default:
+					text, category = vcardAnniversary, "anniversary"
+				}
+				eL = append(eL, Event{Month: month, Day: day, Text: text, Days: 1, Category: category, Origin: year, Name: fn})
+			}
+		}
+	}
+	if card {
+		return nil, &Error{ErrVCard, name, fmt.Errorf("VCARD without END")}
+	}
+	return eL, nil
+}
+
+// readVCardfile reads the vCard file filename.
+func (g *Calendar) readVCardfile(filename string) (eL []Event, err error) {
+	if g.excludedSource(filename, "") {
+		return nil, nil
+	}
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, &Error{ErrVCard, filename, err}
+	}
+	defer f.Close()
+	return g.readVCard(filename, f)
+}